@@ -0,0 +1,336 @@
+package smartcar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is how long responses are cached when CacheParams.TTL is not set.
+const defaultCacheTTL = 30 * time.Second
+
+// CacheParams is a param in smartcar.WithCache
+type CacheParams struct {
+	// TTL is how long responses are cached, PathTTLs overrides it for some paths. A path with a negative TTL is
+	// never cached. Batch responses are cached for the shortest TTL of their paths. Defaults to 30 seconds.
+	TTL      time.Duration
+	PathTTLs map[Key]time.Duration
+	// MaxAge is the default of WithMaxAge, for the requests that don't pass it.
+	MaxAge time.Duration
+	// MaxEntries limits the number of cached responses, there is no limit when it is 0.
+	MaxEntries int
+}
+
+// WithMaxAge makes a request skip cached responses whose data was read from the vehicle more than maxAge ago,
+// according to their DataAge. It only has an effect on clients created with WithCache.
+func WithMaxAge(maxAge time.Duration) RequestOption {
+	return func(p *requestParams) {
+		p.MaxAge = maxAge
+	}
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	vehicleID string
+	body      []byte
+	fetched   time.Time
+	expires   time.Time
+	// dataAge is the oldest DataAge of the response, it is zero when the response had none.
+	dataAge time.Time
+}
+
+// fresh reports whether the entry can be served to a request accepting data up to maxAge old.
+func (e *cacheEntry) fresh(now time.Time, maxAge time.Duration) bool {
+	if !now.Before(e.expires) {
+		return false
+	}
+	if maxAge <= 0 {
+		return true
+	}
+	age := e.dataAge
+	if age.IsZero() {
+		age = e.fetched
+	}
+	return now.Sub(age) <= maxAge
+}
+
+// cacheCall is a request in flight, concurrent identical requests wait for it instead of sending their own. It is
+// sent with a context of its own, canceled once every request waiting for it gave up.
+type cacheCall struct {
+	vehicleID string
+	done      chan struct{}
+	entry     *cacheEntry
+	err       error
+	cancel    context.CancelFunc
+	// waiters counts the requests waiting for the call, and stale is set when a command was sent to the vehicle
+	// while the call was in flight, so its response is not cached. Both are guarded by the mutex of the cache.
+	waiters int
+	stale   bool
+}
+
+// cache is a backendClient that caches the responses of vehicle GET requests and batch requests, keyed by vehicle,
+// access token, path and unit system. Any other request to a vehicle, i.e. a command, drops the cached responses
+// of that vehicle.
+type cache struct {
+	next   backendClient
+	params CacheParams
+	now    func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*cacheEntry
+	inFlight map[string]*cacheCall
+}
+
+// newCache wraps next in a cache.
+func newCache(next backendClient, params *CacheParams) backendClient {
+	c := &cache{
+		next:     next,
+		params:   *params,
+		now:      time.Now,
+		entries:  make(map[string]*cacheEntry),
+		inFlight: make(map[string]*cacheCall),
+	}
+	if c.params.TTL <= 0 {
+		c.params.TTL = defaultCacheTTL
+	}
+	return c
+}
+
+// Call implements backendClient.
+func (c *cache) Call(params backendClientParams) error {
+	if params.vehicleID == "" {
+		return c.next.Call(params)
+	}
+	key, ttl, ok := c.key(&params)
+	if !ok {
+		err := c.next.Call(params)
+		if params.method != http.MethodGet {
+			c.invalidate(params.vehicleID)
+		}
+		return err
+	}
+
+	maxAge := params.requestParams.MaxAge
+	if maxAge <= 0 {
+		maxAge = c.params.MaxAge
+	}
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && entry.fresh(c.now(), maxAge) {
+		c.mu.Unlock()
+		return decodeCacheEntry(entry, params.target)
+	}
+	call, ok := c.inFlight[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(params.ctx))
+		call = &cacheCall{vehicleID: params.vehicleID, done: make(chan struct{}), cancel: cancel}
+		c.inFlight[key] = call
+		fetchParams := params
+		fetchParams.ctx = ctx
+		go c.fetch(key, call, fetchParams, ttl)
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-params.ctx.Done():
+		c.leave(key, call)
+		return params.ctx.Err()
+	}
+	if call.err != nil {
+		return call.err
+	}
+	return decodeCacheEntry(call.entry, params.target)
+}
+
+// leave stops waiting for call, and cancels it when no other request waits for it.
+func (c *cache) leave(key string, call *cacheCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if c.inFlight[key] == call {
+		delete(c.inFlight, key)
+	}
+}
+
+// fetch sends the request of call into a new target, and caches the entry of its response.
+func (c *cache) fetch(key string, call *cacheCall, params backendClientParams, ttl time.Duration) {
+	defer call.cancel()
+	params.target = newTarget(params.target)
+	call.entry, call.err = c.send(params, ttl)
+
+	c.mu.Lock()
+	if c.inFlight[key] == call {
+		delete(c.inFlight, key)
+	}
+	if call.err == nil && !call.stale && cacheable(params.target) {
+		c.store(key, call.entry)
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// send sends the request and returns the entry of its response.
+func (c *cache) send(params backendClientParams, ttl time.Duration) (*cacheEntry, error) {
+	if err := c.next.Call(params); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(params.target)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	return &cacheEntry{
+		vehicleID: params.vehicleID,
+		body:      body,
+		fetched:   now,
+		expires:   now.Add(ttl),
+		dataAge:   oldestDataAge(params.target),
+	}, nil
+}
+
+// cacheable reports whether a response can be cached. Batch responses with failed paths are not cached.
+func cacheable(target interface{}) bool {
+	if batch, ok := target.(*batchResponse); ok {
+		for _, item := range batch.Responses {
+			if batchItemError(item) != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// key returns the cache key and TTL of a request, or false if the request is not cached. It reads the body of
+// batch requests and replaces it with a copy.
+func (c *cache) key(params *backendClientParams) (string, time.Duration, bool) {
+	paths := []string{params.path}
+	switch {
+	case params.method == http.MethodGet:
+	case params.method == http.MethodPost && params.path == string(batchPath):
+		body, err := newReplayableBody(params.body)
+		if err != nil {
+			return "", 0, false
+		}
+		params.body = body()
+		batch := new(struct {
+			Requests []struct {
+				Path string `json:"path"`
+			} `json:"requests"`
+		})
+		if err := json.NewDecoder(body()).Decode(batch); err != nil {
+			return "", 0, false
+		}
+		paths = paths[:0]
+		for _, request := range batch.Requests {
+			paths = append(paths, request.Path)
+		}
+		sort.Strings(paths)
+	default:
+		return "", 0, false
+	}
+
+	var ttl time.Duration
+	for i, path := range paths {
+		pathTTL, ok := c.params.PathTTLs[Key(path)]
+		if !ok {
+			pathTTL = c.params.TTL
+		}
+		if pathTTL < 0 {
+			return "", 0, false
+		}
+		if i == 0 || pathTTL < ttl {
+			ttl = pathTTL
+		}
+	}
+	key := params.vehicleID + "|" + authorizationHash(params.authorization) + "|" + string(params.requestParams.UnitSystem) + "|" + params.path
+	if params.method == http.MethodPost {
+		key += "|" + strings.Join(paths, ",")
+	}
+	return key, ttl, true
+}
+
+// authorizationHash returns a hash of the Authorization header of a request, so that the users sharing a vehicle
+// don't share its responses, without keeping their tokens.
+func authorizationHash(authorization string) string {
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:16])
+}
+
+// store adds an entry, making room for it when the cache is full. c.mu must be held.
+func (c *cache) store(key string, entry *cacheEntry) {
+	if c.params.MaxEntries > 0 && len(c.entries) >= c.params.MaxEntries {
+		now := c.now()
+		var oldestKey string
+		var oldest *cacheEntry
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			} else if oldest == nil || e.fetched.Before(oldest.fetched) {
+				oldestKey, oldest = k, e
+			}
+		}
+		if oldest != nil && len(c.entries) >= c.params.MaxEntries {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// invalidate drops the cached responses of a vehicle, and keeps the responses of its calls in flight from being
+// cached.
+func (c *cache) invalidate(vehicleID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if e.vehicleID == vehicleID {
+			delete(c.entries, k)
+		}
+	}
+	// The responses of calls in flight may predate the command: they are not cached, and later requests send
+	// their own.
+	for k, call := range c.inFlight {
+		if call.vehicleID == vehicleID {
+			call.stale = true
+			delete(c.inFlight, k)
+		}
+	}
+}
+
+// decodeCacheEntry fills target with a cached response.
+func decodeCacheEntry(entry *cacheEntry, target interface{}) error {
+	if err := json.Unmarshal(entry.body, target); err != nil {
+		return err
+	}
+	applyUnitSystem(target)
+	return nil
+}
+
+// oldestDataAge returns the DataAge of a response, or the oldest DataAge of the responses of a batch.
+func oldestDataAge(target interface{}) time.Time {
+	switch target := target.(type) {
+	case batchItem:
+		return target.responseHeaders().DataAge
+	case *batchResponse:
+		var oldest time.Time
+		for _, item := range target.Responses {
+			dataAge := parseDataAge(item.Headers.DataAge)
+			if !dataAge.IsZero() && (oldest.IsZero() || dataAge.Before(oldest)) {
+				oldest = dataAge
+			}
+		}
+		return oldest
+	}
+	return time.Time{}
+}
@@ -0,0 +1,324 @@
+package smartcar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheTestSuite struct {
+	suite.Suite
+	backend *countingBackend
+	cache   *cache
+	vehicle Vehicle
+	now     time.Time
+}
+
+// countingBackend answers every request with the response of its path and counts the requests sent. Requests
+// wait for delay, or for the delay of their path in pathDelays.
+type countingBackend struct {
+	mu         sync.Mutex
+	responses  map[string]string
+	calls      []backendClientParams
+	delay      time.Duration
+	pathDelays map[string]time.Duration
+	err        error
+}
+
+func (b *countingBackend) Call(params backendClientParams) error {
+	delay := b.delay
+	if pathDelay, ok := b.pathDelays[params.path]; ok {
+		delay = pathDelay
+	}
+	if params.ctx != nil {
+		select {
+		case <-time.After(delay):
+		case <-params.ctx.Done():
+			return params.ctx.Err()
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, params)
+	if b.err != nil {
+		return b.err
+	}
+	if err := json.Unmarshal([]byte(b.responses[params.path]), params.target); err != nil {
+		return err
+	}
+	applyUnitSystem(params.target)
+	return nil
+}
+
+func (b *countingBackend) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.calls)
+}
+
+func (s *CacheTestSuite) SetupTest() {
+	s.now = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.backend = &countingBackend{responses: map[string]string{
+		"/odometer": `{"distance": 1000, "dataAge": "2020-01-01T11:50:00Z", "unitSystem": "metric"}`,
+		"/location": `{"latitude": 1, "longitude": 2}`,
+		"/security": `{"status": "success"}`,
+		"/batch": `{"responses": [
+			{"path": "/odometer", "code": 200, "headers": {"sc-data-age": "2020-01-01T11:00:00Z"}, "body": {"distance": 1000}},
+			{"path": "/location", "code": 200, "headers": {"sc-data-age": "2020-01-01T11:55:00Z"}, "body": {"latitude": 1, "longitude": 2}}
+		]}`,
+	}}
+	s.cache = newCache(s.backend, &CacheParams{
+		TTL:      time.Minute,
+		PathTTLs: map[Key]time.Duration{LocationPath: 10 * time.Second, VINPath: -1},
+	}).(*cache)
+	s.cache.now = func() time.Time { return s.now }
+	s.vehicle = s.newVehicle("v1")
+}
+
+func (s *CacheTestSuite) newVehicle(id string) Vehicle {
+	return &vehicle{id: id, accessToken: "token", client: s.cache, requestParams: requestParams{UnitSystem: Metric}}
+}
+
+// failBatch makes the location of the batch responses fail.
+func (s *CacheTestSuite) failBatch() {
+	s.backend.responses["/batch"] = `{"responses": [
+		{"path": "/odometer", "code": 200, "body": {"distance": 1000}},
+		{"path": "/location", "code": 409, "body": {"type": "VEHICLE_STATE", "code": "ASLEEP"}}
+	]}`
+}
+
+func (s *CacheTestSuite) TestHit() {
+	first, err := s.vehicle.GetOdometer(context.Background())
+	second, _ := s.vehicle.GetOdometer(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), first, second)
+	assert.Equal(s.T(), Distance{Value: 1000, Units: Metric}, second.Distance)
+	assert.Equal(s.T(), time.Date(2020, 1, 1, 11, 50, 0, 0, time.UTC), second.DataAge)
+	assert.Equal(s.T(), 1, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestKeys() {
+	s.vehicle.GetOdometer(context.Background())
+
+	s.vehicle.GetOdometer(context.Background(), WithUnits(Imperial))
+	s.newVehicle("v2").GetOdometer(context.Background())
+	s.vehicle.GetLocation(context.Background())
+	// Users sharing a vehicle don't share its responses.
+	otherUser := &vehicle{id: "v1", accessToken: "other-token", client: s.cache, requestParams: requestParams{UnitSystem: Metric}}
+	otherUser.GetOdometer(context.Background())
+
+	assert.Equal(s.T(), 5, s.backend.count())
+	for key := range s.cache.entries {
+		assert.NotContains(s.T(), key, "token")
+	}
+}
+
+func (s *CacheTestSuite) TestTTL() {
+	s.vehicle.GetOdometer(context.Background())
+	s.vehicle.GetLocation(context.Background())
+
+	s.now = s.now.Add(30 * time.Second)
+	s.vehicle.GetOdometer(context.Background())
+	s.vehicle.GetLocation(context.Background())
+
+	assert.Equal(s.T(), 3, s.backend.count())
+	assert.Equal(s.T(), LocationPath, Key(s.backend.calls[2].path))
+}
+
+func (s *CacheTestSuite) TestNeverCachedPath() {
+	s.backend.responses["/vin"] = `{"vin": "vin"}`
+
+	s.vehicle.GetVIN(context.Background())
+	s.vehicle.GetVIN(context.Background())
+
+	assert.Equal(s.T(), 2, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestMaxAge() {
+	// The odometer was read from the vehicle 10 minutes ago.
+	s.vehicle.GetOdometer(context.Background())
+
+	s.vehicle.GetOdometer(context.Background(), WithMaxAge(15*time.Minute))
+	hits := s.backend.count()
+	s.vehicle.GetOdometer(context.Background(), WithMaxAge(5*time.Minute))
+
+	assert.Equal(s.T(), 1, hits)
+	assert.Equal(s.T(), 2, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestBatch() {
+	first, err := s.vehicle.Batch(context.Background(), OdometerPath, LocationPath)
+	second, _ := s.vehicle.Batch(context.Background(), LocationPath, OdometerPath)
+	s.now = s.now.Add(15 * time.Second)
+	s.vehicle.Batch(context.Background(), OdometerPath, LocationPath)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), first, second)
+	assert.Equal(s.T(), 1000.0, second.Odometer.Distance.Value)
+	// The batch expires with the location, its shortest TTL.
+	assert.Equal(s.T(), 2, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestBatchOldestDataAge() {
+	cache := newCache(s.backend, &CacheParams{MaxAge: 30 * time.Minute}).(*cache)
+	cache.now = s.cache.now
+	v := &vehicle{id: "v1", client: cache, requestParams: requestParams{UnitSystem: Metric}}
+
+	// The odometer of the batch was read an hour ago.
+	v.Batch(context.Background(), OdometerPath, LocationPath)
+	v.Batch(context.Background(), OdometerPath, LocationPath)
+
+	assert.Equal(s.T(), 2, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestCommandsInvalidate() {
+	s.vehicle.GetOdometer(context.Background())
+	s.newVehicle("v2").GetOdometer(context.Background())
+
+	s.vehicle.Lock(context.Background())
+	s.vehicle.GetOdometer(context.Background())
+	s.newVehicle("v2").GetOdometer(context.Background())
+
+	assert.Equal(s.T(), 4, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestCommandDuringFetch() {
+	s.backend.pathDelays = map[string]time.Duration{"/odometer": 30 * time.Millisecond}
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.vehicle.GetOdometer(context.Background())
+	}()
+	time.Sleep(5 * time.Millisecond)
+	s.vehicle.Lock(context.Background())
+	wg.Wait()
+	s.backend.pathDelays = nil
+	s.vehicle.GetOdometer(context.Background())
+
+	// The response of the fetch that started before the command is not cached.
+	assert.Equal(s.T(), 3, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestErrorsAreNotCached() {
+	s.backend.err = errors.New("vehicle is asleep")
+
+	_, err := s.vehicle.GetOdometer(context.Background())
+	s.backend.err = nil
+	res, _ := s.vehicle.GetOdometer(context.Background())
+
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), 1000.0, res.Distance.Value)
+	assert.Equal(s.T(), 2, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestSingleflight() {
+	s.backend.delay = 20 * time.Millisecond
+	var wg sync.WaitGroup
+	results := make([]*Odometer, 10)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = s.vehicle.GetOdometer(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(s.T(), 1, s.backend.count())
+	for _, res := range results {
+		assert.Equal(s.T(), 1000.0, res.Distance.Value)
+	}
+}
+
+func (s *CacheTestSuite) TestSingleflightCanceledRequest() {
+	s.backend.delay = 30 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	var canceledErr, err error
+	var res *Odometer
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, canceledErr = s.vehicle.GetOdometer(ctx)
+	}()
+	time.Sleep(time.Millisecond)
+	go func() {
+		defer wg.Done()
+		res, err = s.vehicle.GetOdometer(context.Background())
+	}()
+	wg.Wait()
+
+	// The request that gave up first does not fail the request waiting for the same response.
+	assert.Equal(s.T(), context.DeadlineExceeded, canceledErr)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1000.0, res.Distance.Value)
+	assert.Equal(s.T(), 1, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestSingleflightAllCanceled() {
+	s.backend.delay = 20 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.vehicle.GetOdometer(ctx)
+	_, nextErr := s.vehicle.GetOdometer(context.Background())
+
+	assert.Equal(s.T(), context.Canceled, err)
+	assert.Nil(s.T(), nextErr)
+}
+
+func (s *CacheTestSuite) TestBatchWithFailedPathIsNotCached() {
+	s.failBatch()
+
+	first, err := s.vehicle.Batch(context.Background(), OdometerPath, LocationPath)
+	s.vehicle.Batch(context.Background(), OdometerPath, LocationPath)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "ASLEEP", first.Errors[LocationPath].Code)
+	assert.Equal(s.T(), 2, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestMaxEntries() {
+	cache := newCache(s.backend, &CacheParams{MaxEntries: 2}).(*cache)
+	cache.now = s.cache.now
+	newVehicle := func(id string) Vehicle {
+		return &vehicle{id: id, client: cache, requestParams: requestParams{UnitSystem: Metric}}
+	}
+
+	newVehicle("v1").GetOdometer(context.Background())
+	s.now = s.now.Add(time.Second)
+	newVehicle("v2").GetOdometer(context.Background())
+	newVehicle("v3").GetOdometer(context.Background())
+	newVehicle("v1").GetOdometer(context.Background())
+
+	assert.Len(s.T(), cache.entries, 2)
+	assert.Equal(s.T(), 4, s.backend.count())
+}
+
+func (s *CacheTestSuite) TestOtherRequests() {
+	client := &client{sC: s.cache}
+	s.backend.responses[""] = `{"id": "user"}`
+
+	client.GetUserID(context.Background(), &UserIDParams{Access: "token"})
+	client.GetUserID(context.Background(), &UserIDParams{Access: "token"})
+
+	assert.Equal(s.T(), 2, s.backend.count())
+	assert.Equal(s.T(), http.MethodGet, s.backend.calls[0].method)
+}
+
+func TestCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheTestSuite))
+}
@@ -0,0 +1,232 @@
+// Package charging detects the charging sessions of vehicles from their Charge and Battery readings.
+package charging
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// stateCharging is the Charge.State of a vehicle that is charging.
+const stateCharging = "CHARGING"
+
+// EventType is the type of an Event.
+type EventType string
+
+// EventType constants
+const (
+	Started EventType = "started"
+	Ended   EventType = "ended"
+)
+
+// Reading is a set of readings of a vehicle at the same time. Charge is required, the other fields may be nil.
+type Reading struct {
+	Time            time.Time
+	Charge          *smartcar.Charge
+	Battery         *smartcar.Battery
+	BatteryCapacity *smartcar.BatteryCapacity
+	Location        *smartcar.Location
+}
+
+// NewReading returns a Reading from the response of vehicle.Batch, at the DataAge of its Charge.
+func NewReading(data *smartcar.Data) *Reading {
+	reading := &Reading{
+		Charge:          data.Charge,
+		Battery:         data.Battery,
+		BatteryCapacity: data.BatteryCapacity,
+		Location:        data.Location,
+	}
+	if data.Charge != nil {
+		reading.Time = data.Charge.DataAge
+	}
+	return reading
+}
+
+// Session is a charging session of a vehicle.
+type Session struct {
+	VehicleID string
+	// End is zero while the session is in progress.
+	Start, End time.Time
+	// StartPercent and EndPercent are the state of charge of the battery, between 0 and 1. EndPercent is the highest
+	// state of charge read during the session, since the vehicle may have been driven before the reading that ended it.
+	StartPercent, EndPercent float64
+	// Capacity is the capacity of the battery in kWh, or 0 when it was never read.
+	Capacity float64
+	// Location is the last location of the vehicle known at the start of the session, and Place the name
+	// TrackerParams.Locate gave it.
+	Location *smartcar.Location
+	Place    string
+	// Partial is true when the vehicle was already charging at its first reading, so the session started earlier.
+	Partial bool
+}
+
+// PercentAdded returns the state of charge gained during the session, between 0 and 1.
+func (s *Session) PercentAdded() float64 {
+	return s.EndPercent - s.StartPercent
+}
+
+// EnergyAdded returns an estimate of the energy added to the battery, in kWh, from the state of charge gained and
+// the capacity of the battery.
+func (s *Session) EnergyAdded() float64 {
+	return s.PercentAdded() * s.Capacity
+}
+
+// Duration returns the duration of a session that ended.
+func (s *Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Event is reported when a session started or ended.
+type Event struct {
+	Type    EventType
+	Session *Session
+}
+
+// TrackerParams is a param in charging.NewTracker
+type TrackerParams struct {
+	// MaxPause is how long a vehicle that is still plugged in can stop charging, i.e. for scheduled charging,
+	// without ending its session. A session ends on the first reading where the vehicle is not charging when it is 0.
+	MaxPause time.Duration
+	// Locate optionally names the location of a session, i.e. with a geofence.Evaluator.
+	Locate func(location *smartcar.Location) string
+}
+
+// Tracker keeps track of the charging sessions of vehicles. It is safe for concurrent use.
+type Tracker interface {
+	// Add updates the state of the vehicle with a new reading and returns the resulting event, if any. Readings
+	// without a Charge, or older than the previous one, are ignored.
+	Add(vehicleID string, reading *Reading) *Event
+	// Current returns a copy of the session in progress of the vehicle, or nil.
+	Current(vehicleID string) *Session
+	// Forget drops the state of a vehicle.
+	Forget(vehicleID string)
+}
+
+// tracker implements the Tracker interface.
+type tracker struct {
+	maxPause time.Duration
+	locate   func(location *smartcar.Location) string
+
+	mu       sync.Mutex
+	vehicles map[string]*vehicleState
+}
+
+type vehicleState struct {
+	last     time.Time
+	percent  *float64
+	capacity float64
+	location *smartcar.Location
+
+	// session is the session in progress, pausedAt the time the vehicle stopped charging during it.
+	session  *Session
+	pausedAt time.Time
+}
+
+// NewTracker creates a Tracker.
+func NewTracker(params *TrackerParams) Tracker {
+	return &tracker{
+		maxPause: params.MaxPause,
+		locate:   params.Locate,
+		vehicles: make(map[string]*vehicleState),
+	}
+}
+
+// Add implements Tracker.
+func (t *tracker) Add(vehicleID string, reading *Reading) *Event {
+	if reading.Charge == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	vehicle, seen := t.vehicles[vehicleID]
+	if !seen {
+		vehicle = &vehicleState{}
+		t.vehicles[vehicleID] = vehicle
+	} else if reading.Time.Before(vehicle.last) {
+		return nil
+	}
+	vehicle.last = reading.Time
+	if reading.Battery != nil {
+		percent := reading.Battery.PercentRemaining
+		vehicle.percent = &percent
+	}
+	if reading.BatteryCapacity != nil {
+		vehicle.capacity = reading.BatteryCapacity.Capacity
+	}
+	if reading.Location != nil {
+		vehicle.location = reading.Location
+	}
+
+	charging := reading.Charge.IsPluggedIn && reading.Charge.State == stateCharging
+	session := vehicle.session
+	switch {
+	case session == nil && charging:
+		session = &Session{
+			VehicleID: vehicleID,
+			Start:     reading.Time,
+			Capacity:  vehicle.capacity,
+			Location:  vehicle.location,
+			Partial:   !seen,
+		}
+		if vehicle.percent != nil {
+			session.StartPercent, session.EndPercent = *vehicle.percent, *vehicle.percent
+		}
+		if session.Location != nil && t.locate != nil {
+			session.Place = t.locate(session.Location)
+		}
+		vehicle.session = session
+		return &Event{Type: Started, Session: copySession(session)}
+	case session == nil:
+		return nil
+	}
+
+	if vehicle.percent != nil {
+		session.EndPercent = math.Max(session.EndPercent, *vehicle.percent)
+	}
+	if vehicle.capacity > 0 {
+		session.Capacity = vehicle.capacity
+	}
+	if charging {
+		vehicle.pausedAt = time.Time{}
+		return nil
+	}
+	if vehicle.pausedAt.IsZero() {
+		vehicle.pausedAt = reading.Time
+	}
+	if reading.Charge.IsPluggedIn && reading.Time.Sub(vehicle.pausedAt) < t.maxPause {
+		return nil
+	}
+
+	session.End = vehicle.pausedAt
+	vehicle.session, vehicle.pausedAt = nil, time.Time{}
+	return &Event{Type: Ended, Session: session}
+}
+
+// Current implements Tracker.
+func (t *tracker) Current(vehicleID string) *Session {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	vehicle, ok := t.vehicles[vehicleID]
+	if !ok || vehicle.session == nil {
+		return nil
+	}
+	return copySession(vehicle.session)
+}
+
+// Forget implements Tracker.
+func (t *tracker) Forget(vehicleID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.vehicles, vehicleID)
+}
+
+// copySession returns a copy of a session in progress, so callers don't race with the tracker updating it.
+func copySession(session *Session) *Session {
+	c := *session
+	return &c
+}
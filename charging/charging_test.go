@@ -0,0 +1,171 @@
+package charging
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TrackerTestSuite struct {
+	suite.Suite
+	start   time.Time
+	tracker Tracker
+}
+
+func (s *TrackerTestSuite) SetupTest() {
+	s.start = time.Date(2020, 1, 1, 20, 0, 0, 0, time.UTC)
+	s.tracker = NewTracker(&TrackerParams{})
+}
+
+// reading returns a reading minutes after the start of the test.
+func (s *TrackerTestSuite) reading(minutes int, state string, percent float64) *Reading {
+	return &Reading{
+		Time:    s.start.Add(time.Duration(minutes) * time.Minute),
+		Charge:  &smartcar.Charge{IsPluggedIn: state != "", State: state},
+		Battery: &smartcar.Battery{PercentRemaining: percent},
+	}
+}
+
+func (s *TrackerTestSuite) TestSession() {
+	home := &smartcar.Location{Latitude: 48.8566, Longitude: 2.3522}
+	tracker := NewTracker(&TrackerParams{
+		Locate: func(location *smartcar.Location) string {
+			if location.DistanceTo(home) < 50 {
+				return "home"
+			}
+			return ""
+		},
+	})
+	first := s.reading(0, "", 0.2)
+	first.Location = home
+	first.BatteryCapacity = &smartcar.BatteryCapacity{Capacity: 75}
+
+	assert.Nil(s.T(), tracker.Add("car", first))
+	started := tracker.Add("car", s.reading(1, "NOT_CHARGING", 0.2))
+	assert.Nil(s.T(), started)
+	started = tracker.Add("car", s.reading(2, "CHARGING", 0.21))
+	charging := tracker.Add("car", s.reading(60, "CHARGING", 0.5))
+	current := tracker.Current("car")
+	ended := tracker.Add("car", s.reading(120, "FULLY_CHARGED", 0.8))
+
+	assert.Equal(s.T(), Started, started.Type)
+	assert.Nil(s.T(), charging)
+	assert.Equal(s.T(), 0.5, current.EndPercent)
+	assert.Equal(s.T(), Ended, ended.Type)
+	session := ended.Session
+	assert.Equal(s.T(), "car", session.VehicleID)
+	assert.Equal(s.T(), s.start.Add(2*time.Minute), session.Start)
+	assert.Equal(s.T(), s.start.Add(120*time.Minute), session.End)
+	assert.Equal(s.T(), 118*time.Minute, session.Duration())
+	assert.InDelta(s.T(), 0.59, session.PercentAdded(), 1e-9)
+	assert.InDelta(s.T(), 44.25, session.EnergyAdded(), 1e-9)
+	assert.Equal(s.T(), home, session.Location)
+	assert.Equal(s.T(), "home", session.Place)
+	assert.False(s.T(), session.Partial)
+	assert.Nil(s.T(), tracker.Current("car"))
+}
+
+func (s *TrackerTestSuite) TestUnplugged() {
+	s.tracker.Add("car", s.reading(0, "CHARGING", 0.5))
+
+	ended := s.tracker.Add("car", s.reading(30, "", 0.6))
+
+	assert.Equal(s.T(), Ended, ended.Type)
+	assert.True(s.T(), ended.Session.Partial)
+	assert.InDelta(s.T(), 0.1, ended.Session.PercentAdded(), 1e-9)
+	// The capacity was never read.
+	assert.Equal(s.T(), 0.0, ended.Session.EnergyAdded())
+}
+
+func (s *TrackerTestSuite) TestDrivenBeforeEndReading() {
+	start := s.reading(0, "CHARGING", 0.2)
+	start.BatteryCapacity = &smartcar.BatteryCapacity{Capacity: 80}
+	s.tracker.Add("car", start)
+	s.tracker.Add("car", s.reading(60, "CHARGING", 0.8))
+
+	// The vehicle was unplugged and driven before the next reading.
+	ended := s.tracker.Add("car", s.reading(120, "", 0.5))
+
+	assert.Equal(s.T(), Ended, ended.Type)
+	assert.Equal(s.T(), 0.8, ended.Session.EndPercent)
+	assert.InDelta(s.T(), 48, ended.Session.EnergyAdded(), 1e-9)
+}
+
+func (s *TrackerTestSuite) TestPause() {
+	tracker := NewTracker(&TrackerParams{MaxPause: time.Hour})
+	tracker.Add("car", s.reading(0, "NOT_CHARGING", 0.3))
+	tracker.Add("car", s.reading(1, "CHARGING", 0.3))
+
+	paused := tracker.Add("car", s.reading(30, "NOT_CHARGING", 0.4))
+	resumed := tracker.Add("car", s.reading(60, "CHARGING", 0.4))
+	tracker.Add("car", s.reading(90, "NOT_CHARGING", 0.5))
+	stillPaused := tracker.Add("car", s.reading(120, "NOT_CHARGING", 0.5))
+	ended := tracker.Add("car", s.reading(150, "NOT_CHARGING", 0.5))
+
+	assert.Nil(s.T(), paused)
+	assert.Nil(s.T(), resumed)
+	assert.Nil(s.T(), stillPaused)
+	assert.Equal(s.T(), Ended, ended.Type)
+	// The session ended when the vehicle stopped charging.
+	assert.Equal(s.T(), s.start.Add(90*time.Minute), ended.Session.End)
+	assert.InDelta(s.T(), 0.2, ended.Session.PercentAdded(), 1e-9)
+}
+
+func (s *TrackerTestSuite) TestIgnoredReadings() {
+	s.tracker.Add("car", s.reading(10, "NOT_CHARGING", 0.3))
+
+	stale := s.tracker.Add("car", s.reading(5, "CHARGING", 0.3))
+	withoutCharge := s.tracker.Add("car", &Reading{Time: s.start.Add(20 * time.Minute)})
+
+	assert.Nil(s.T(), stale)
+	assert.Nil(s.T(), withoutCharge)
+	assert.Nil(s.T(), s.tracker.Current("car"))
+}
+
+func (s *TrackerTestSuite) TestForget() {
+	s.tracker.Add("car", s.reading(0, "CHARGING", 0.3))
+
+	s.tracker.Forget("car")
+
+	assert.Nil(s.T(), s.tracker.Current("car"))
+}
+
+func (s *TrackerTestSuite) TestNewReading() {
+	age := s.start.Add(time.Hour)
+	data := &smartcar.Data{
+		Charge:  &smartcar.Charge{State: "CHARGING", ResponseHeaders: smartcar.ResponseHeaders{DataAge: age}},
+		Battery: &smartcar.Battery{PercentRemaining: 0.4},
+	}
+
+	reading := NewReading(data)
+
+	assert.Equal(s.T(), age, reading.Time)
+	assert.Equal(s.T(), data.Charge, reading.Charge)
+	assert.Equal(s.T(), data.Battery, reading.Battery)
+	assert.Nil(s.T(), reading.Location)
+}
+
+func (s *TrackerTestSuite) TestConcurrentVehicles() {
+	var wg sync.WaitGroup
+	for _, id := range []string{"car", "van", "truck"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.tracker.Add(id, s.reading(i, "CHARGING", float64(i)/100))
+				s.tracker.Current(id)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	assert.Equal(s.T(), 0.99, s.tracker.Current("van").EndPercent)
+}
+
+func TestTrackerTestSuite(t *testing.T) {
+	suite.Run(t, new(TrackerTestSuite))
+}
@@ -0,0 +1,343 @@
+package smartcar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults of the zero fields of CircuitBreakerParams.
+const (
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = 30 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+// CircuitState is the state of a circuit of a CircuitBreaker.
+type CircuitState int
+
+// States of a circuit. Requests go through closed circuits. Open circuits reject requests with an
+// *CircuitOpenError until their OpenDuration elapsed, then they are half open: a few requests probe the endpoint,
+// and the circuit closes when they succeed or opens again when they fail.
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// ErrCircuitOpen matches the errors of the requests rejected by an open circuit with errors.Is.
+var ErrCircuitOpen = errors.New("smartcar: circuit open")
+
+// CircuitOpenError is the error of the requests rejected by an open circuit, without being sent.
+type CircuitOpenError struct {
+	Path Key
+	Make string
+	// RetryAfter is how long until the circuit lets a request probe the endpoint again.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *CircuitOpenError) Error() string {
+	circuit := string(e.Path)
+	if e.Make != "" {
+		circuit += " (" + e.Make + ")"
+	}
+	return fmt.Sprintf("smartcar: circuit open for %s, retry after %s", circuit, e.RetryAfter)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitBreakerParams is a param in smartcar.NewCircuitBreaker
+type CircuitBreakerParams struct {
+	// FailureThreshold is the number of consecutive failed requests that opens a circuit, it defaults to 5.
+	FailureThreshold int
+	// OpenDuration is how long a circuit stays open before it is half open, it defaults to 30 seconds.
+	// HalfOpenRequests is how many requests can probe a half open circuit at once, it defaults to 1.
+	OpenDuration     time.Duration
+	HalfOpenRequests int
+	// ByMake splits the circuits of endpoints by vehicle make, once vehicle.GetInfo returned the make of a vehicle.
+	// The requests of vehicles whose make is unknown share a circuit.
+	ByMake bool
+	// IsFailure reports whether the error of a request counts as a failure. It defaults to errors that could be an
+	// outage: 5xx errors, timeouts and network errors. Requests canceled by the application are never counted.
+	IsFailure func(error) bool
+	// OnStateChange is called when a circuit changes state, i.e. to monitor circuits.
+	OnStateChange func(CircuitStatus)
+}
+
+// CircuitStatus is the status of a circuit.
+type CircuitStatus struct {
+	// Path is the endpoint of vehicle requests, i.e. OdometerPath, or the path of the URL of other requests. Make
+	// is set when the circuits are split ByMake.
+	Path  Key
+	Make  string
+	State CircuitState
+	// Failures is the number of consecutive failed requests, and OpenedAt when the circuit last opened.
+	Failures int
+	OpenedAt time.Time
+}
+
+// circuitKey identifies a circuit.
+type circuitKey struct {
+	path        Key
+	vehicleMake string
+}
+
+// circuit is the state of the circuit of an endpoint.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// probes counts the requests probing a half open circuit.
+	probes int
+}
+
+// CircuitBreaker fails requests fast when their endpoint keeps failing, i.e. when the backend of a make is down,
+// instead of waiting for every request to time out. A CircuitBreaker can be shared by clients.
+type CircuitBreaker struct {
+	params CircuitBreakerParams
+	now    func() time.Time
+
+	mu       sync.Mutex
+	circuits map[circuitKey]*circuit
+}
+
+// NewCircuitBreaker returns a CircuitBreaker with closed circuits, to use in smartcar.WithCircuitBreaker.
+func NewCircuitBreaker(params *CircuitBreakerParams) *CircuitBreaker {
+	p := *params
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = defaultFailureThreshold
+	}
+	if p.OpenDuration <= 0 {
+		p.OpenDuration = defaultOpenDuration
+	}
+	if p.HalfOpenRequests <= 0 {
+		p.HalfOpenRequests = defaultHalfOpenRequests
+	}
+	if p.IsFailure == nil {
+		p.IsFailure = isOutage
+	}
+	return &CircuitBreaker{params: p, now: time.Now, circuits: map[circuitKey]*circuit{}}
+}
+
+// WithCircuitBreaker rejects the requests of the client with a *CircuitOpenError while their circuit in breaker is
+// open. Responses served by WithCache don't go through the circuit breaker, while a request retried by
+// WithRateLimit is a single request.
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return func(o *clientOptions) {
+		o.circuitBreaker = breaker
+	}
+}
+
+// isOutage reports whether err could be caused by an outage of Smartcar's API or of the backend of a make.
+func isOutage(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	// The request was canceled by the application, not by a timeout. The http client wraps the error in a *url.Error.
+	return !errors.Is(err, context.Canceled)
+}
+
+// circuit returns the circuit of key, moving it from open to half open once its OpenDuration elapsed. It must be
+// called with b.mu held, and returns the status of the circuit when it changed state.
+func (b *CircuitBreaker) circuit(key circuitKey, now time.Time) (*circuit, *CircuitStatus) {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	if c.state == CircuitOpen && !now.Before(c.openedAt.Add(b.params.OpenDuration)) {
+		c.state, c.probes = CircuitHalfOpen, 0
+		return c, b.status(key, c)
+	}
+	return c, nil
+}
+
+// status returns the status of the circuit c of key.
+func (b *CircuitBreaker) status(key circuitKey, c *circuit) *CircuitStatus {
+	return &CircuitStatus{Path: key.path, Make: key.vehicleMake, State: c.state, Failures: c.failures, OpenedAt: c.openedAt}
+}
+
+// notify calls OnStateChange with the statuses of the circuits that changed state.
+func (b *CircuitBreaker) notify(changes ...*CircuitStatus) {
+	if b.params.OnStateChange == nil {
+		return
+	}
+	for _, status := range changes {
+		if status != nil {
+			b.params.OnStateChange(*status)
+		}
+	}
+}
+
+// allow reports whether a request can go through the circuit of key, and whether it probes a half open circuit.
+func (b *CircuitBreaker) allow(key circuitKey) (bool, error) {
+	now := b.now()
+	b.mu.Lock()
+	c, change := b.circuit(key, now)
+	var err error
+	probe := false
+	switch c.state {
+	case CircuitOpen:
+		err = &CircuitOpenError{Path: key.path, Make: key.vehicleMake, RetryAfter: c.openedAt.Add(b.params.OpenDuration).Sub(now)}
+	case CircuitHalfOpen:
+		if c.probes >= b.params.HalfOpenRequests {
+			err = &CircuitOpenError{Path: key.path, Make: key.vehicleMake}
+		} else {
+			c.probes++
+			probe = true
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(change)
+	return probe, err
+}
+
+// record records the outcome of a request allowed by the circuit of key. Requests canceled by the application
+// neither fail nor succeed: a canceled probe only frees its slot.
+func (b *CircuitBreaker) record(key circuitKey, probe bool, err error) {
+	canceled := errors.Is(err, context.Canceled)
+	failed := !canceled && b.params.IsFailure(err)
+	now := b.now()
+	b.mu.Lock()
+	c, change := b.circuit(key, now)
+	var outcome *CircuitStatus
+	switch {
+	case canceled:
+		if probe && c.state == CircuitHalfOpen {
+			c.probes--
+		}
+	case probe && c.state == CircuitHalfOpen:
+		c.probes--
+		if failed {
+			c.state, c.openedAt = CircuitOpen, now
+			c.failures++
+		} else {
+			c.state, c.failures = CircuitClosed, 0
+		}
+		outcome = b.status(key, c)
+	case !probe && c.state == CircuitClosed:
+		// Requests allowed before the circuit opened don't change it once it is open.
+		if !failed {
+			c.failures = 0
+			break
+		}
+		c.failures++
+		if c.failures >= b.params.FailureThreshold {
+			c.state, c.openedAt = CircuitOpen, now
+			outcome = b.status(key, c)
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(change, outcome)
+}
+
+// Circuits returns the status of every circuit that was used, sorted by path and make.
+func (b *CircuitBreaker) Circuits() []CircuitStatus {
+	now := b.now()
+	b.mu.Lock()
+	statuses := make([]CircuitStatus, 0, len(b.circuits))
+	var changes []*CircuitStatus
+	for key := range b.circuits {
+		c, change := b.circuit(key, now)
+		statuses = append(statuses, *b.status(key, c))
+		changes = append(changes, change)
+	}
+	b.mu.Unlock()
+
+	b.notify(changes...)
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Path != statuses[j].Path {
+			return statuses[i].Path < statuses[j].Path
+		}
+		return statuses[i].Make < statuses[j].Make
+	})
+	return statuses
+}
+
+// State returns the state of the circuit of path for vehicles of a make, "" being the vehicles whose make is
+// unknown or every vehicle when the circuits are not split ByMake.
+func (b *CircuitBreaker) State(path Key, vehicleMake string) CircuitState {
+	key := circuitKey{path: path, vehicleMake: vehicleMake}
+	now := b.now()
+	b.mu.Lock()
+	if _, ok := b.circuits[key]; !ok {
+		b.mu.Unlock()
+		return CircuitClosed
+	}
+	c, change := b.circuit(key, now)
+	state := c.state
+	b.mu.Unlock()
+
+	b.notify(change)
+	return state
+}
+
+// breakerBackend is a backendClient sending requests through the circuits of a CircuitBreaker.
+type breakerBackend struct {
+	next    backendClient
+	breaker *CircuitBreaker
+	makes   *vehicleMakes
+}
+
+// newBreakerBackend wraps next in a breakerBackend.
+func newBreakerBackend(next backendClient, breaker *CircuitBreaker, makes *vehicleMakes) backendClient {
+	return &breakerBackend{next: next, breaker: breaker, makes: makes}
+}
+
+// key returns the key of the circuit of a request.
+func (b *breakerBackend) key(params backendClientParams) circuitKey {
+	key := circuitKey{path: Key(params.path)}
+	if key.path == "" {
+		if u, err := url.Parse(params.url); err == nil {
+			key.path = Key(u.Path)
+		}
+	}
+	if b.breaker.params.ByMake {
+		key.vehicleMake = b.makes.get(params.vehicleID)
+	}
+	return key
+}
+
+// Call implements backendClient.
+func (b *breakerBackend) Call(params backendClientParams) error {
+	key := b.key(params)
+	probe, err := b.breaker.allow(key)
+	if err != nil {
+		return err
+	}
+
+	err = b.next.Call(params)
+	if err == nil {
+		b.makes.learn(params.vehicleID, params.target)
+	}
+	b.breaker.record(key, probe, err)
+	return err
+}
@@ -0,0 +1,193 @@
+package smartcar
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CircuitBreakerTestSuite struct {
+	suite.Suite
+	now     time.Time
+	changes []CircuitStatus
+	next    *countingBackend
+	breaker *CircuitBreaker
+	client  backendClient
+}
+
+func (s *CircuitBreakerTestSuite) SetupTest() {
+	s.now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.changes = nil
+	s.next = &countingBackend{responses: map[string]string{
+		string(InfoPath):     `{"id":"vehicle-id","make":"TESLA"}`,
+		string(OdometerPath): `{"distance":10}`,
+	}}
+	s.breaker = NewCircuitBreaker(&CircuitBreakerParams{
+		FailureThreshold: 2,
+		OpenDuration:     time.Minute,
+		ByMake:           true,
+		OnStateChange:    func(status CircuitStatus) { s.changes = append(s.changes, status) },
+	})
+	s.breaker.now = func() time.Time { return s.now }
+	s.client = newBreakerBackend(s.next, s.breaker, newVehicleMakes())
+}
+
+// call sends a request to the path of a vehicle.
+func (s *CircuitBreakerTestSuite) call(vehicleID string, path Key) error {
+	var target interface{} = &Odometer{}
+	if path == InfoPath {
+		target = &Info{}
+	}
+	return s.client.Call(backendClientParams{
+		ctx:       context.Background(),
+		url:       buildVehicleURL(string(path), vehicleID),
+		vehicleID: vehicleID,
+		path:      string(path),
+		target:    target,
+	})
+}
+
+func (s *CircuitBreakerTestSuite) TestOpen() {
+	s.next.err = &Error{StatusCode: http.StatusBadGateway}
+
+	firstErr := s.call("vehicle-id", OdometerPath)
+	secondErr := s.call("vehicle-id", OdometerPath)
+	s.now = s.now.Add(10 * time.Second)
+	openErr := s.call("vehicle-id", OdometerPath)
+	otherErr := s.call("vehicle-id", LocationPath)
+
+	s.Equal(http.StatusBadGateway, firstErr.(*Error).StatusCode)
+	s.Equal(http.StatusBadGateway, secondErr.(*Error).StatusCode)
+	s.Equal(&CircuitOpenError{Path: OdometerPath, RetryAfter: 50 * time.Second}, openErr)
+	s.Equal("smartcar: circuit open for /odometer, retry after 50s", openErr.Error())
+	s.True(errors.Is(openErr, ErrCircuitOpen))
+	// Other endpoints have their own circuit.
+	s.Equal(http.StatusBadGateway, otherErr.(*Error).StatusCode)
+	s.Len(s.next.calls, 3)
+	s.Equal(CircuitOpen, s.breaker.State(OdometerPath, ""))
+	s.Equal([]CircuitStatus{{Path: OdometerPath, State: CircuitOpen, Failures: 2, OpenedAt: s.now.Add(-10 * time.Second)}}, s.changes)
+}
+
+func (s *CircuitBreakerTestSuite) TestNotFailures() {
+	s.next.err = &Error{StatusCode: http.StatusConflict, Type: "VEHICLE_STATE"}
+	s.call("vehicle-id", OdometerPath)
+	s.call("vehicle-id", OdometerPath)
+	s.next.err = context.Canceled
+	s.call("vehicle-id", OdometerPath)
+	s.next.err = &url.Error{Op: "Get", URL: "https://api.smartcar.com/v2.0/vehicles/vehicle-id/odometer", Err: context.Canceled}
+	s.call("vehicle-id", OdometerPath)
+	s.next.err = errors.New("connection refused")
+	s.call("vehicle-id", OdometerPath)
+	s.next.err = nil
+	s.call("vehicle-id", OdometerPath)
+	s.next.err = errors.New("connection refused")
+	s.call("vehicle-id", OdometerPath)
+
+	// Successful requests reset the failures.
+	s.Equal([]CircuitStatus{{Path: OdometerPath, State: CircuitClosed, Failures: 1}}, s.breaker.Circuits())
+}
+
+func (s *CircuitBreakerTestSuite) TestCanceledKeepsFailures() {
+	s.next.err = &Error{StatusCode: http.StatusBadGateway}
+	s.call("vehicle-id", OdometerPath)
+	s.next.err = context.Canceled
+	s.call("vehicle-id", OdometerPath)
+	s.next.err = &Error{StatusCode: http.StatusBadGateway}
+	s.call("vehicle-id", OdometerPath)
+
+	s.Equal(CircuitOpen, s.breaker.State(OdometerPath, ""))
+}
+
+func (s *CircuitBreakerTestSuite) TestCanceledProbe() {
+	s.breaker.circuits[circuitKey{path: OdometerPath}] = &circuit{state: CircuitHalfOpen, failures: 2}
+	s.next.err = &url.Error{Op: "Get", URL: "https://api.smartcar.com/v2.0/vehicles/vehicle-id/odometer", Err: context.Canceled}
+
+	canceledErr := s.call("vehicle-id", OdometerPath)
+	probe, probeErr := s.breaker.allow(circuitKey{path: OdometerPath})
+
+	s.True(errors.Is(canceledErr, context.Canceled))
+	s.True(probe)
+	s.Nil(probeErr)
+	s.Equal([]CircuitStatus{{Path: OdometerPath, State: CircuitHalfOpen, Failures: 2}}, s.breaker.Circuits())
+	s.Empty(s.changes)
+}
+
+func (s *CircuitBreakerTestSuite) TestHalfOpen() {
+	s.next.err = &Error{StatusCode: http.StatusServiceUnavailable}
+	s.call("vehicle-id", OdometerPath)
+	s.call("vehicle-id", OdometerPath)
+
+	// A failed probe opens the circuit again.
+	s.now = s.now.Add(time.Minute)
+	s.Equal(CircuitHalfOpen, s.breaker.State(OdometerPath, ""))
+	probeErr := s.call("vehicle-id", OdometerPath)
+	openErr := s.call("vehicle-id", OdometerPath)
+
+	// A successful probe closes the circuit.
+	s.now = s.now.Add(time.Minute)
+	s.next.err = nil
+	probeSuccess := s.call("vehicle-id", OdometerPath)
+	closedSuccess := s.call("vehicle-id", OdometerPath)
+
+	s.Equal(http.StatusServiceUnavailable, probeErr.(*Error).StatusCode)
+	s.IsType(&CircuitOpenError{}, openErr)
+	s.Nil(probeSuccess)
+	s.Nil(closedSuccess)
+	s.Len(s.next.calls, 5)
+	var states []CircuitState
+	for _, change := range s.changes {
+		states = append(states, change.State)
+	}
+	s.Equal([]CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}, states)
+}
+
+func (s *CircuitBreakerTestSuite) TestHalfOpenProbes() {
+	s.breaker.circuits[circuitKey{path: OdometerPath}] = &circuit{state: CircuitHalfOpen}
+
+	probe, probeErr := s.breaker.allow(circuitKey{path: OdometerPath})
+	_, rejectedErr := s.breaker.allow(circuitKey{path: OdometerPath})
+
+	s.True(probe)
+	s.Nil(probeErr)
+	s.IsType(&CircuitOpenError{}, rejectedErr)
+}
+
+func (s *CircuitBreakerTestSuite) TestByMake() {
+	s.call("tesla", InfoPath)
+	s.next.err = &Error{StatusCode: http.StatusGatewayTimeout}
+	s.call("tesla", OdometerPath)
+	s.call("tesla", OdometerPath)
+
+	teslaErr := s.call("tesla", OdometerPath)
+	otherErr := s.call("other", OdometerPath)
+
+	s.Equal(&CircuitOpenError{Path: OdometerPath, Make: "TESLA", RetryAfter: time.Minute}, teslaErr)
+	s.Equal("smartcar: circuit open for /odometer (TESLA), retry after 1m0s", teslaErr.Error())
+	s.Equal(http.StatusGatewayTimeout, otherErr.(*Error).StatusCode)
+	s.Equal(CircuitOpen, s.breaker.State(OdometerPath, "TESLA"))
+	s.Equal(CircuitClosed, s.breaker.State(OdometerPath, ""))
+	s.Len(s.breaker.Circuits(), 3)
+}
+
+func (s *CircuitBreakerTestSuite) TestWithCircuitBreaker() {
+	client := NewClient(
+		WithFaults(&FaultParams{Script: []*Fault{ServerErrorFault(http.StatusBadGateway), ServerErrorFault(http.StatusBadGateway)}}),
+		WithCircuitBreaker(s.breaker),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	vehicle.GetOdometer(context.Background())
+	vehicle.GetOdometer(context.Background())
+	_, err := vehicle.GetOdometer(context.Background())
+
+	s.IsType(&CircuitOpenError{}, err)
+}
+
+func TestCircuitBreakerTestSuite(t *testing.T) {
+	suite.Run(t, new(CircuitBreakerTestSuite))
+}
@@ -0,0 +1,100 @@
+package smartcar
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxErrorBodyBytes limits how much of an error response is read.
+const maxErrorBodyBytes = 1 << 16
+
+// Error is returned when Smartcar's API responds with a status code other than 200.
+// Type, Message and Code are filled from the response body when Smartcar's API includes them, i.e. "VEHICLE_STATE"
+// and "ASLEEP". Message is the description of version 2.0 errors.
+type Error struct {
+	StatusCode int    `json:"statusCode"`
+	Type       string `json:"error"`
+	Message    string `json:"message"`
+	Code       string `json:"code"`
+	RequestID  string `json:"requestId,omitempty"`
+	// RetryAfter is how long to wait before sending the request again, it is set from the Retry-After or
+	// RateLimit-Reset header of 429 Too Many Requests responses.
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Error formats the error, it always starts with the status text of the response.
+func (e *Error) Error() string {
+	message := http.StatusText(e.StatusCode)
+	if e.Type != "" {
+		message += ": " + e.Type
+	}
+	if e.Message != "" {
+		message += " - " + e.Message
+	}
+	return message
+}
+
+// newError builds an Error from an error response.
+func newError(res *http.Response) *Error {
+	var body interface{}
+	b, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+	json.Unmarshal(b, &body)
+
+	err := &Error{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("Sc-Request-Id"),
+		RetryAfter: parseRetryAfter(res.Header),
+	}
+	err.decodeBody(body)
+	return err
+}
+
+// batchItemError returns the error of a failed path of a batch response, or nil. Responses without a code, as in
+// some webhook deliveries, succeeded.
+func batchItemError(res batchResponseItem) *Error {
+	if res.Code == 0 || res.Code == http.StatusOK {
+		return nil
+	}
+	err := &Error{StatusCode: res.Code, RequestID: res.Headers.RequestID}
+	err.decodeBody(res.Body)
+	return err
+}
+
+// decodeBody sets the Type, Message and Code of e from the decoded body of an error response. Version 2.0 of
+// Smartcar's API sends type and description, version 1.0 error and message, and authentication errors follow the
+// OAuth format with error and error_description.
+func (e *Error) decodeBody(body interface{}) {
+	fields, _ := body.(map[string]interface{})
+	field := func(keys ...string) string {
+		for _, key := range keys {
+			if value, _ := fields[key].(string); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	e.Type = field("type", "error")
+	e.Message = field("description", "message", "error_description")
+	e.Code = field("code")
+}
+
+// parseRetryAfter reads the Retry-After header, in seconds or as an HTTP date, or the RateLimit-Reset header,
+// in seconds. It returns 0 when neither is valid.
+func parseRetryAfter(headers http.Header) time.Duration {
+	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil && date.After(time.Now()) {
+			return time.Until(date)
+		}
+	}
+	if seconds, err := strconv.Atoi(headers.Get("RateLimit-Reset")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
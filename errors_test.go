@@ -0,0 +1,137 @@
+package smartcar
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMessage(t *testing.T) {
+	// Arrange
+	withoutBody := &Error{StatusCode: 429}
+	withType := &Error{StatusCode: 409, Type: "VEHICLE_STATE"}
+	withMessage := &Error{StatusCode: 409, Type: "VEHICLE_STATE", Message: "The vehicle is asleep."}
+
+	// Act & Assert
+	assert.EqualError(t, withoutBody, "Too Many Requests")
+	assert.EqualError(t, withType, "Conflict: VEHICLE_STATE")
+	assert.EqualError(t, withMessage, "Conflict: VEHICLE_STATE - The vehicle is asleep.")
+}
+
+func TestNewError(t *testing.T) {
+	// Arrange
+	res := &http.Response{
+		StatusCode: 409,
+		Header:     http.Header{"Sc-Request-Id": []string{"request-id"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"statusCode":409,"type":"VEHICLE_STATE","code":"ASLEEP","description":"The vehicle is asleep."}`)),
+	}
+
+	// Act
+	err := newError(res)
+
+	// Assert
+	assert.Equal(t, &Error{
+		StatusCode: 409,
+		Type:       "VEHICLE_STATE",
+		Message:    "The vehicle is asleep.",
+		Code:       "ASLEEP",
+		RequestID:  "request-id",
+	}, err)
+}
+
+func TestNewErrorV1(t *testing.T) {
+	// Arrange
+	res := &http.Response{
+		StatusCode: 409,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"error":"VEHICLE_STATE","message":"The vehicle is asleep.","code":"VS_004"}`)),
+	}
+
+	// Act
+	err := newError(res)
+
+	// Assert
+	assert.Equal(t, &Error{StatusCode: 409, Type: "VEHICLE_STATE", Message: "The vehicle is asleep.", Code: "VS_004"}, err)
+}
+
+func TestBatchItemError(t *testing.T) {
+	// Arrange
+	v2 := batchResponseItem{Path: "/location", Code: 409, Body: map[string]interface{}{
+		"type": "VEHICLE_STATE", "code": "ASLEEP", "description": "The vehicle is asleep.",
+	}}
+	v1 := batchResponseItem{Path: "/location", Code: 409, Body: map[string]interface{}{
+		"error": "VEHICLE_STATE", "code": "VS_004", "message": "The vehicle is asleep.",
+	}}
+	v2.Headers.RequestID = "request-id"
+	ok := batchResponseItem{Path: "/odometer", Code: 200, Body: map[string]interface{}{"distance": 1.0}}
+
+	// Act & Assert
+	assert.Equal(t, &Error{StatusCode: 409, Type: "VEHICLE_STATE", Code: "ASLEEP", Message: "The vehicle is asleep.", RequestID: "request-id"}, batchItemError(v2))
+	assert.Equal(t, &Error{StatusCode: 409, Type: "VEHICLE_STATE", Code: "VS_004", Message: "The vehicle is asleep."}, batchItemError(v1))
+	assert.Nil(t, batchItemError(ok))
+}
+
+func TestNewErrorOAuth(t *testing.T) {
+	// Arrange
+	res := &http.Response{
+		StatusCode: 400,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"error":"invalid_grant","error_description":"Invalid or expired refresh token."}`)),
+	}
+
+	// Act
+	err := newError(res)
+
+	// Assert
+	assert.Equal(t, "invalid_grant", err.Type)
+	assert.Equal(t, "Invalid or expired refresh token.", err.Message)
+}
+
+func TestNewErrorNotJSON(t *testing.T) {
+	// Arrange
+	res := &http.Response{
+		StatusCode: 502,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`<html>Bad Gateway</html>`)),
+	}
+
+	// Act
+	err := newError(res)
+
+	// Assert
+	assert.Equal(t, &Error{StatusCode: 502}, err)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	// Arrange
+	seconds := http.Header{"Retry-After": []string{"120"}}
+	date := http.Header{"Retry-After": []string{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}}
+	reset := http.Header{"Ratelimit-Reset": []string{"30"}}
+	invalid := http.Header{"Retry-After": []string{"soon"}}
+
+	// Act & Assert
+	assert.Equal(t, 2*time.Minute, parseRetryAfter(seconds))
+	assert.InDelta(t, float64(time.Hour), float64(parseRetryAfter(date)), float64(2*time.Second))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(reset))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(invalid))
+}
+
+func TestNewErrorRetryAfter(t *testing.T) {
+	// Arrange
+	res := &http.Response{
+		StatusCode: 429,
+		Header:     http.Header{"Retry-After": []string{"5"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"RATE_LIMIT","code":"VEHICLE"}`)),
+	}
+
+	// Act
+	err := newError(res)
+
+	// Assert
+	assert.Equal(t, 5*time.Second, err.RetryAfter)
+	assert.Equal(t, "VEHICLE", err.Code)
+}
@@ -0,0 +1,227 @@
+package smartcar
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault is a failure injected by WithFaults. Its fields can be combined, i.e. a latency before an error.
+type Fault struct {
+	// Latency delays the request.
+	Latency time.Duration
+	// Error is returned instead of sending the request. When BatchPaths is set, it is instead the response of
+	// those paths in the response of batch requests.
+	Error      *Error
+	BatchPaths []Key
+	// Truncated sends the request, so commands take effect, but its response can't be decoded.
+	Truncated bool
+	// ConnectionReset fails the request with a connection reset, without sending it.
+	ConnectionReset bool
+}
+
+// LatencyFault delays requests by d.
+func LatencyFault(d time.Duration) *Fault {
+	return &Fault{Latency: d}
+}
+
+// RateLimitFault rejects requests with a 429 Too Many Requests asking to retry after retryAfter.
+func RateLimitFault(retryAfter time.Duration) *Fault {
+	return &Fault{Error: &Error{
+		StatusCode: http.StatusTooManyRequests,
+		Type:       "RATE_LIMIT",
+		Code:       "VEHICLE",
+		Message:    "Injected fault: too many requests.",
+		RetryAfter: retryAfter,
+	}}
+}
+
+// ServerErrorFault rejects requests with a 5xx statusCode.
+func ServerErrorFault(statusCode int) *Fault {
+	return &Fault{Error: &Error{
+		StatusCode: statusCode,
+		Type:       "SERVER",
+		Code:       "INTERNAL",
+		Message:    "Injected fault: server error.",
+	}}
+}
+
+// AsleepFault rejects requests because the vehicle is asleep.
+func AsleepFault() *Fault {
+	return &Fault{Error: asleepError()}
+}
+
+// TruncatedFault makes the responses of requests unreadable.
+func TruncatedFault() *Fault {
+	return &Fault{Truncated: true}
+}
+
+// ConnectionResetFault fails requests with a connection reset.
+func ConnectionResetFault() *Fault {
+	return &Fault{ConnectionReset: true}
+}
+
+// BatchPathFault makes paths fail with err in the responses of batch requests, err defaults to a vehicle asleep error.
+func BatchPathFault(err *Error, paths ...Key) *Fault {
+	if err == nil {
+		err = asleepError()
+	}
+	return &Fault{Error: err, BatchPaths: paths}
+}
+
+func asleepError() *Error {
+	return &Error{
+		StatusCode: http.StatusConflict,
+		Type:       "VEHICLE_STATE",
+		Code:       "ASLEEP",
+		Message:    "Injected fault: the vehicle is asleep.",
+	}
+}
+
+// FaultRule injects a fault in a random share of the requests.
+type FaultRule struct {
+	Fault *Fault
+	// Probability is the share of the requests that get the fault, between 0 and 1.
+	Probability float64
+	// Paths limits the fault to the requests to these vehicle paths, i.e. BatteryPath. The path of batch requests
+	// is "/batch". Every request can get the fault when it is empty.
+	Paths []Key
+}
+
+// FaultParams is a param in smartcar.WithFaults
+type FaultParams struct {
+	// Script is the faults of the first requests, in order. A nil Fault lets its request through.
+	Script []*Fault
+	// Rules inject faults at random once the script is over, the first rule that applies to a request is used.
+	// Seed seeds the random faults, so that a test always gets the same faults for the same requests.
+	Rules []FaultRule
+	Seed  int64
+}
+
+// WithFaults injects faults in the requests of the client, to test how an application copes with errors. Faults
+// are injected before the requests reach the network, retries of WithRateLimit go through them again.
+func WithFaults(params *FaultParams) ClientOption {
+	return func(o *clientOptions) {
+		o.faults = params
+	}
+}
+
+// faultInjector is a backendClient that injects faults in the requests it sends to next.
+type faultInjector struct {
+	next  backendClient
+	rules []FaultRule
+
+	mu     sync.Mutex
+	script []*Fault
+	random *rand.Rand
+}
+
+// newFaultInjector wraps next in a faultInjector.
+func newFaultInjector(next backendClient, params *FaultParams) backendClient {
+	return &faultInjector{
+		next:   next,
+		rules:  params.Rules,
+		script: append([]*Fault(nil), params.Script...),
+		random: rand.New(rand.NewSource(params.Seed)),
+	}
+}
+
+// fault returns the fault of a request, or nil.
+func (f *faultInjector) fault(params *backendClientParams) *Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.script) > 0 {
+		fault := f.script[0]
+		f.script = f.script[1:]
+		return fault
+	}
+	for _, rule := range f.rules {
+		if !matchesPath(rule.Paths, params.path) {
+			continue
+		}
+		// Every matching rule draws a number, so the faults of a request don't depend on the rules before.
+		if f.random.Float64() < rule.Probability {
+			return rule.Fault
+		}
+	}
+	return nil
+}
+
+// matchesPath reports whether path is one of paths, or paths is empty.
+func matchesPath(paths []Key, path string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if string(p) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Call implements backendClient.
+func (f *faultInjector) Call(params backendClientParams) error {
+	fault := f.fault(&params)
+	if fault == nil {
+		return f.next.Call(params)
+	}
+
+	if err := sleep(params.ctx, fault.Latency); err != nil {
+		return err
+	}
+	if fault.ConnectionReset {
+		return &url.Error{
+			Op:  params.method,
+			URL: params.url,
+			Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+		}
+	}
+	if fault.Error != nil && len(fault.BatchPaths) == 0 {
+		err := *fault.Error
+		return &err
+	}
+
+	if err := f.next.Call(params); err != nil {
+		return err
+	}
+	if fault.Truncated {
+		return truncatedError(params.target)
+	}
+	if response, ok := params.target.(*batchResponse); ok && fault.Error != nil {
+		failBatchPaths(response, fault.Error, fault.BatchPaths)
+	}
+	return nil
+}
+
+// truncatedError returns the error of decoding the first half of the JSON encoding of target.
+func truncatedError(target interface{}) error {
+	b, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b[:len(b)/2], new(interface{}))
+}
+
+// failBatchPaths replaces the responses of paths with err in a batch response.
+func failBatchPaths(response *batchResponse, err *Error, paths []Key) {
+	for i := range response.Responses {
+		item := &response.Responses[i]
+		if !matchesPath(paths, item.Path) {
+			continue
+		}
+		item.Code = err.StatusCode
+		item.Headers.DataAge, item.Headers.UnitSystem = "", ""
+		item.Body = map[string]interface{}{
+			"type":        err.Type,
+			"description": err.Message,
+			"code":        err.Code,
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package smartcar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchBackend answers batch requests with a successful response for every path of the request.
+type batchBackend struct {
+	calls int
+}
+
+func (b *batchBackend) Call(params backendClientParams) error {
+	b.calls++
+	request := new(struct {
+		Requests []struct{ Path string } `json:"requests"`
+	})
+	if params.body != nil {
+		json.NewDecoder(params.body).Decode(request)
+	}
+	if response, ok := params.target.(*batchResponse); ok {
+		for _, r := range request.Requests {
+			item := batchResponseItem{Path: r.Path, Code: http.StatusOK, Body: map[string]interface{}{"distance": 10}}
+			item.Headers.UnitSystem = Metric
+			response.Responses = append(response.Responses, item)
+		}
+	}
+	if odometer, ok := params.target.(*Odometer); ok {
+		odometer.Distance.Value = 10
+	}
+	return nil
+}
+
+func TestFaultsScript(t *testing.T) {
+	// Arrange
+	next := &batchBackend{}
+	injector := newFaultInjector(next, &FaultParams{Script: []*Fault{
+		RateLimitFault(time.Second),
+		nil,
+		ServerErrorFault(http.StatusBadGateway),
+		AsleepFault(),
+		ConnectionResetFault(),
+		TruncatedFault(),
+	}})
+	call := func() error {
+		return injector.Call(backendClientParams{ctx: context.Background(), target: &Odometer{}})
+	}
+
+	// Act
+	rateLimitErr := call()
+	passedErr := call()
+	serverErr := call()
+	asleepErr := call()
+	resetErr := call()
+	truncatedErr := call()
+	afterErr := call()
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitErr.(*Error).StatusCode)
+	assert.Equal(t, time.Second, rateLimitErr.(*Error).RetryAfter)
+	assert.Nil(t, passedErr)
+	assert.Equal(t, http.StatusBadGateway, serverErr.(*Error).StatusCode)
+	assert.Equal(t, "VEHICLE_STATE", asleepErr.(*Error).Type)
+	assert.Equal(t, "ASLEEP", asleepErr.(*Error).Code)
+	opErr, ok := resetErr.(*url.Error).Err.(*net.OpError)
+	assert.True(t, ok)
+	assert.Contains(t, opErr.Error(), "connection reset")
+	assert.IsType(t, &json.SyntaxError{}, truncatedErr)
+	assert.Nil(t, afterErr)
+	// Only the request that passed, the truncated one and the one after the script were sent.
+	assert.Equal(t, 3, next.calls)
+}
+
+func TestFaultsLatency(t *testing.T) {
+	// Arrange
+	injector := newFaultInjector(&batchBackend{}, &FaultParams{Script: []*Fault{
+		LatencyFault(30 * time.Millisecond),
+		LatencyFault(time.Hour),
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+
+	// Act
+	err := injector.Call(backendClientParams{ctx: context.Background(), target: &Odometer{}})
+	elapsed := time.Since(start)
+	cancelErr := injector.Call(backendClientParams{ctx: ctx, target: &Odometer{}})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, elapsed >= 30*time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded, cancelErr)
+}
+
+func TestFaultsBatchPaths(t *testing.T) {
+	// Arrange
+	injector := newFaultInjector(&batchBackend{}, &FaultParams{Script: []*Fault{
+		BatchPathFault(nil, LocationPath),
+	}})
+	target := new(batchResponse)
+
+	// Act
+	err := injector.Call(backendClientParams{
+		ctx:    context.Background(),
+		body:   bytes.NewBufferString(`{"requests":[{"path":"/odometer"},{"path":"/location"}]}`),
+		target: target,
+	})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, target.Responses[0].Code)
+	assert.Equal(t, http.StatusConflict, target.Responses[1].Code)
+	assert.Equal(t, "ASLEEP", target.Responses[1].Body.(map[string]interface{})["code"])
+}
+
+func TestFaultsRules(t *testing.T) {
+	// Arrange
+	newInjector := func() backendClient {
+		return newFaultInjector(&batchBackend{}, &FaultParams{
+			Rules: []FaultRule{
+				{Fault: AsleepFault(), Probability: 1, Paths: []Key{LocationPath}},
+				{Fault: ServerErrorFault(http.StatusInternalServerError), Probability: 0.5},
+			},
+			Seed: 42,
+		})
+	}
+	run := func(injector backendClient) []bool {
+		var failed []bool
+		for i := 0; i < 100; i++ {
+			err := injector.Call(backendClientParams{ctx: context.Background(), path: string(OdometerPath), target: &Odometer{}})
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+	injector := newInjector()
+
+	// Act
+	locationErr := injector.Call(backendClientParams{ctx: context.Background(), path: string(LocationPath), target: &Location{}})
+	first, second := run(newInjector()), run(newInjector())
+
+	// Assert
+	assert.Equal(t, "ASLEEP", locationErr.(*Error).Code)
+	assert.Equal(t, first, second)
+	failures := 0
+	for _, failed := range first {
+		if failed {
+			failures++
+		}
+	}
+	assert.True(t, failures > 30 && failures < 70, failures)
+}
+
+func TestFaultsWithRateLimit(t *testing.T) {
+	// Arrange
+	client := NewClient(
+		WithFaults(&FaultParams{Script: []*Fault{RateLimitFault(10 * time.Millisecond), RateLimitFault(10 * time.Millisecond)}}),
+		WithRateLimit(&RateLimitParams{MaxRetries: 2}),
+		WithBaseURL("http://127.0.0.1:1"),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	_, err := vehicle.GetOdometer(context.Background())
+
+	// Assert
+	// Both faults were retried, the third attempt reached the network.
+	_, ok := err.(*url.Error)
+	assert.True(t, ok, err)
+}
@@ -0,0 +1,253 @@
+package smartcar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// FixtureMode is the mode of smartcar.WithFixtures.
+type FixtureMode int
+
+// FixtureMode constants
+const (
+	// FixtureRecord sends requests and writes them, with their responses, to the fixture file.
+	FixtureRecord FixtureMode = iota
+	// FixtureReplay answers requests with the responses of the fixture file, without sending them.
+	FixtureReplay
+)
+
+// redacted replaces secrets in fixtures.
+const redacted = "REDACTED"
+
+// FixtureParams is a param in smartcar.WithFixtures
+type FixtureParams struct {
+	Mode FixtureMode
+	// Path is the JSON file holding the fixtures. Recording overwrites it.
+	Path string
+}
+
+// WithFixtures records the requests of the client and their responses to a file, or replays them from that file.
+// Recorded fixtures don't hold the authorization header of requests, and access tokens, refresh tokens, authorization
+// codes and VINs are redacted. When replaying, every recorded response is used once, in the order it was recorded,
+// and requests without a matching response fail.
+func WithFixtures(params *FixtureParams) ClientOption {
+	return func(o *clientOptions) {
+		o.fixtures = params
+	}
+}
+
+// fixture is a recorded request and its response.
+type fixture struct {
+	Request  fixtureRequest  `json:"request"`
+	Response fixtureResponse `json:"response"`
+}
+
+// fixtureRequest is a recorded request. URL only holds the path and query, so recordings made against a fake
+// server can be replayed against Smartcar's API.
+type fixtureRequest struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	UnitSystem string `json:"unitSystem,omitempty"`
+	Body       string `json:"body,omitempty"`
+}
+
+// fixtureResponse is a recorded response.
+type fixtureResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+}
+
+// fixtureFile is the content of a fixture file.
+type fixtureFile struct {
+	Fixtures []*fixture `json:"fixtures"`
+}
+
+// fixtureTransport is an http.RoundTripper that records or replays fixtures.
+type fixtureTransport struct {
+	params FixtureParams
+	// next sends the requests that are recorded, it is http.DefaultTransport when nil.
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	loaded   bool
+	fixtures []*fixture
+	used     []bool
+}
+
+// newFixtureClient returns a copy of httpClient whose requests go through a fixtureTransport.
+func newFixtureClient(httpClient *http.Client, params *FixtureParams) *http.Client {
+	c := &http.Client{Timeout: defaultHTTPTimeout}
+	if httpClient != nil {
+		*c = *httpClient
+	}
+	c.Transport = &fixtureTransport{params: *params, next: c.Transport}
+	return c
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := newFixtureRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.params.Mode == FixtureReplay {
+		return t.replay(req, request)
+	}
+	return t.record(req, request)
+}
+
+// record sends req and writes it with its response to the fixture file.
+func (t *fixtureTransport) record(req *http.Request, request *fixtureRequest) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fixtures = append(t.fixtures, &fixture{
+		Request: *request,
+		Response: fixtureResponse{
+			StatusCode: res.StatusCode,
+			Headers:    res.Header,
+			Body:       redact(string(body), isJSON(res.Header.Get("Content-Type"), body)),
+		},
+	})
+	b, err := json.MarshalIndent(&fixtureFile{Fixtures: t.fixtures}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(t.params.Path, b, 0644); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// replay answers req with the first unused fixture matching it.
+func (t *fixtureTransport) replay(req *http.Request, request *fixtureRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.loaded {
+		b, err := ioutil.ReadFile(t.params.Path)
+		if err != nil {
+			return nil, err
+		}
+		file := new(fixtureFile)
+		if err := json.Unmarshal(b, file); err != nil {
+			return nil, err
+		}
+		t.fixtures, t.used, t.loaded = file.Fixtures, make([]bool, len(file.Fixtures)), true
+	}
+
+	for i, f := range t.fixtures {
+		if t.used[i] || f.Request != *request {
+			continue
+		}
+		t.used[i] = true
+		headers := http.Header{}
+		for k, v := range f.Response.Headers {
+			headers[k] = v
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+			StatusCode:    f.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        headers,
+			Body:          ioutil.NopCloser(strings.NewReader(f.Response.Body)),
+			ContentLength: int64(len(f.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, errors.New("smartcar: no recorded response for " + request.Method + " " + request.URL)
+}
+
+// newFixtureRequest returns the redacted fixture of req, leaving the body of req readable.
+func newFixtureRequest(req *http.Request) (*fixtureRequest, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	u := req.URL.Path
+	if req.URL.RawQuery != "" {
+		u += "?" + redact(req.URL.RawQuery, false)
+	}
+	return &fixtureRequest{
+		Method:     req.Method,
+		URL:        u,
+		UnitSystem: req.Header.Get("Sc-Unit-System"),
+		Body:       redact(string(body), isJSON(req.Header.Get("Content-Type"), body)),
+	}, nil
+}
+
+// isJSON reports whether a body is JSON, from its content type or its first character.
+func isJSON(contentType string, body []byte) bool {
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+var (
+	// redactedJSONFields matches the string values of the JSON fields holding secrets.
+	redactedJSONFields = regexp.MustCompile(`"(access_token|refresh_token|vin)"(\s*:\s*)"[^"]*"`)
+	// redactedFormFields matches the values of the form fields and query parameters holding secrets.
+	redactedFormFields = regexp.MustCompile(`(^|&)(code|refresh_token|access_token|client_secret|vin)=[^&]*`)
+	// vinPattern matches the VINs left in other fields.
+	vinPattern = regexp.MustCompile(`\b[A-HJ-NPR-Z0-9]{17}\b`)
+)
+
+// redact replaces the tokens, authorization codes and VINs of a JSON or form encoded body, or of a query.
+func redact(s string, json bool) string {
+	if json {
+		s = redactedJSONFields.ReplaceAllString(s, `"$1"$2"`+redacted+`"`)
+	} else {
+		s = redactedFormFields.ReplaceAllString(s, "${1}${2}="+redacted)
+	}
+	return vinPattern.ReplaceAllStringFunc(s, func(match string) string {
+		// A VIN always has letters and digits.
+		if strings.IndexAny(match, "0123456789") < 0 || strings.IndexAny(match, "ABCDEFGHJKLMNPRSTUVWXYZ") < 0 {
+			return match
+		}
+		return redacted
+	})
+}
+
+// redactURL redacts the VINs of the path and the secrets of the query of rawURL.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = redact(u.RawQuery, false)
+	u.Path = redact(u.Path, false)
+	u.RawPath = ""
+	return u.String()
+}
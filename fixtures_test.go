@@ -0,0 +1,117 @@
+package smartcar
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFixtureServer returns a server answering the VIN, odometer and token endpoints.
+func newFixtureServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2.0/vehicles/vehicle-id/vin":
+			w.Header().Set("Sc-Request-Id", "request-id")
+			w.Write([]byte(`{"vin":"5YJ3E1EA1JF000001"}`))
+		case "/v2.0/vehicles/vehicle-id/odometer":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"type":"VEHICLE_STATE","code":"ASLEEP","description":"Vehicle 5YJ3E1EA1JF000001 is asleep."}`))
+		case "/oauth/token/":
+			w.Write([]byte(`{"access_token":"secret-access","refresh_token":"secret-refresh","expires_in":7200}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestFixturesRecordReplay(t *testing.T) {
+	// Arrange
+	dir, _ := ioutil.TempDir("", "fixtures")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fixtures.json")
+	server := newFixtureServer()
+	recorder := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithFixtures(&FixtureParams{Mode: FixtureRecord, Path: path}),
+	)
+	vehicle := recorder.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "secret-token"})
+	auth := recorder.NewAuth(&AuthParams{ClientID: "client-id", ClientSecret: "client-secret"})
+
+	// Act
+	recordedVIN, _ := vehicle.GetVIN(context.Background())
+	_, recordedErr := vehicle.GetOdometer(context.Background())
+	auth.ExchangeCode(context.Background(), &ExchangeCodeParams{Code: "secret-code"})
+	server.Close()
+
+	replayer := NewClient(WithFixtures(&FixtureParams{Mode: FixtureReplay, Path: path}))
+	vehicle = replayer.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "other-token"})
+	auth = replayer.NewAuth(&AuthParams{ClientID: "client-id", ClientSecret: "client-secret"})
+	vin, err := vehicle.GetVIN(context.Background())
+	_, odometerErr := vehicle.GetOdometer(context.Background())
+	token, tokenErr := auth.ExchangeCode(context.Background(), &ExchangeCodeParams{Code: "other-code"})
+	_, unmatchedErr := vehicle.GetVIN(context.Background())
+
+	// Assert
+	assert.Equal(t, "5YJ3E1EA1JF000001", recordedVIN.VIN)
+	assert.Equal(t, "ASLEEP", recordedErr.(*Error).Code)
+	assert.Nil(t, err)
+	assert.Equal(t, "REDACTED", vin.VIN)
+	assert.Equal(t, "request-id", vin.RequestID)
+	assert.Equal(t, http.StatusConflict, odometerErr.(*Error).StatusCode)
+	assert.Equal(t, "ASLEEP", odometerErr.(*Error).Code)
+	assert.Nil(t, tokenErr)
+	assert.Equal(t, 7200, token.ExpiresIn)
+	assert.Contains(t, unmatchedErr.Error(), "no recorded response for GET /v2.0/vehicles/vehicle-id/vin")
+
+	b, _ := ioutil.ReadFile(path)
+	for _, secret := range []string{"5YJ3E1EA1JF000001", "secret-token", "secret-access", "secret-refresh", "secret-code", "client-secret"} {
+		assert.NotContains(t, string(b), secret)
+	}
+	assert.True(t, json.Valid(b))
+}
+
+func TestFixturesReplayMissingFile(t *testing.T) {
+	// Arrange
+	client := NewClient(WithFixtures(&FixtureParams{Mode: FixtureReplay, Path: filepath.Join(os.TempDir(), "missing.json")}))
+
+	// Act
+	_, err := client.GetUserID(context.Background(), &UserIDParams{Access: "token"})
+
+	// Assert
+	assert.NotNil(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name, input, expected string
+		json                  bool
+	}{
+		{"JSON tokens", `{"access_token": "a", "refresh_token":"b","expires_in":7200}`, `{"access_token": "REDACTED", "refresh_token":"REDACTED","expires_in":7200}`, true},
+		{"JSON VIN", `{"vin":"anything","code":"ASLEEP"}`, `{"vin":"REDACTED","code":"ASLEEP"}`, true},
+		{"form", "grant_type=authorization_code&code=abc&redirect_uri=x", "grant_type=authorization_code&code=REDACTED&redirect_uri=x", false},
+		{"query", "vin=5YJ3E1EA1JF000001&scope=read_vin", "vin=REDACTED&scope=read_vin", false},
+		{"VIN in text", `{"message":"Vehicle 1FTFW1E50JF000002 is asleep."}`, `{"message":"Vehicle REDACTED is asleep."}`, true},
+		{"not a VIN", `{"code":"VEHICLE_NOT_CAPABLE","id":"ABCDEFGHJKLMNPRST"}`, `{"code":"VEHICLE_NOT_CAPABLE","id":"ABCDEFGHJKLMNPRST"}`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, redact(test.input, test.json))
+		})
+	}
+}
+
+func TestIsJSON(t *testing.T) {
+	assert.True(t, isJSON("application/json; charset=utf-8", nil))
+	assert.True(t, isJSON("", []byte(` {"a":1}`)))
+	assert.False(t, isJSON("application/x-www-form-urlencoded", []byte("a=1")))
+}
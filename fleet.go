@@ -0,0 +1,160 @@
+package smartcar
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultFleetWorkers is the number of vehicles processed at once when FleetParams.Workers is not set.
+const defaultFleetWorkers = 10
+
+// FleetVehicle is a vehicle of a Fleet.
+type FleetVehicle struct {
+	ID          string
+	TokenSource TokenSource
+	// UnitSystem defaults to Metric.
+	UnitSystem UnitSystem
+}
+
+// FleetParams is a param in client.NewFleet
+type FleetParams struct {
+	Vehicles []FleetVehicle
+	// Workers is the number of vehicles processed at once. Defaults to 10.
+	Workers int
+	// RateLimit is the number of requests per second sent for the whole fleet, and VehicleRateLimit the number of
+	// requests per second sent for each vehicle. There is no limit when they are 0. They replace the limits of
+	// WithRateLimit for the requests of the fleet, which are still retried up to its MaxRetries.
+	RateLimit        float64
+	VehicleRateLimit float64
+}
+
+// FleetResult is the result of an operation on one vehicle of a Fleet. Value is the value returned by the
+// operation, i.e. a *Data for fleet.Batch.
+type FleetResult struct {
+	VehicleID string
+	Value     interface{}
+	Err       error
+}
+
+// FleetFunc is an operation run on every vehicle of a Fleet.
+type FleetFunc func(ctx context.Context, vehicle Vehicle) (interface{}, error)
+
+// Fleet runs operations on many vehicles at once. Results are streamed on the returned channel, in the order the
+// operations complete, and the channel is closed once every vehicle was processed. Once ctx is done no new vehicles
+// are processed, the channel is closed when the running operations returned and their results may be dropped.
+type Fleet interface {
+	// Do runs fn on every vehicle.
+	Do(ctx context.Context, fn FleetFunc) <-chan *FleetResult
+	// Batch calls vehicle.Batch with paths on every vehicle.
+	Batch(ctx context.Context, paths ...Key) <-chan *FleetResult
+}
+
+// fleet implements the Fleet interface.
+type fleet struct {
+	vehicles []FleetVehicle
+	workers  int
+	client   backendClient
+}
+
+// NewFleet creates a Fleet. The requests of the fleet go through the cache and circuit breaker of the client, and
+// are rate limited by the limits of the fleet instead of those of the client.
+func (c *client) NewFleet(params *FleetParams) Fleet {
+	workers := params.Workers
+	if workers <= 0 {
+		workers = defaultFleetWorkers
+	}
+
+	limiter := newRateLimiter(nil, &RateLimitParams{
+		ApplicationRate:  params.RateLimit,
+		ApplicationBurst: int(params.RateLimit),
+		VehicleRate:      params.VehicleRateLimit,
+		VehicleBurst:     1,
+	}).(*rateLimiter)
+	return &fleet{
+		vehicles: params.Vehicles,
+		workers:  workers,
+		client:   &fleetBackend{next: c.sC, limiter: limiter},
+	}
+}
+
+// fleetBackend sends the requests of a fleet through the client, to be rate limited by the limiter of the fleet.
+type fleetBackend struct {
+	next    backendClient
+	limiter *rateLimiter
+}
+
+// Call implements backendClient.
+func (b *fleetBackend) Call(params backendClientParams) error {
+	params.rateLimiter = b.limiter
+	return b.next.Call(params)
+}
+
+// Batch implements Fleet.
+func (f *fleet) Batch(ctx context.Context, paths ...Key) <-chan *FleetResult {
+	return f.Do(ctx, func(ctx context.Context, v Vehicle) (interface{}, error) {
+		return v.Batch(ctx, paths...)
+	})
+}
+
+// Do implements Fleet.
+func (f *fleet) Do(ctx context.Context, fn FleetFunc) <-chan *FleetResult {
+	results := make(chan *FleetResult)
+	jobs := make(chan FleetVehicle)
+
+	var wg sync.WaitGroup
+	for i := 0; i < f.workers && i < len(f.vehicles); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range jobs {
+				result := f.run(ctx, v, fn)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+		for _, v := range f.vehicles {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case jobs <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results
+}
+
+// run runs fn on a vehicle.
+func (f *fleet) run(ctx context.Context, v FleetVehicle, fn FleetFunc) *FleetResult {
+	result := &FleetResult{VehicleID: v.ID}
+
+	accessToken, err := v.TokenSource.Token(ctx)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	unitSystem := Metric
+	if v.UnitSystem != "" {
+		unitSystem = v.UnitSystem
+	}
+	result.Value, result.Err = fn(ctx, &vehicle{
+		id:            v.ID,
+		accessToken:   accessToken,
+		client:        f.client,
+		requestParams: requestParams{UnitSystem: unitSystem},
+	})
+	return result
+}
@@ -0,0 +1,220 @@
+package smartcar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FleetTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+	client Client
+
+	mu          sync.Mutex
+	running     int
+	maxRunning  int
+	requests    map[string]int
+	delay       time.Duration
+	unavailable map[string]bool
+}
+
+func (s *FleetTestSuite) SetupTest() {
+	s.running, s.maxRunning, s.delay = 0, 0, 0
+	s.requests = make(map[string]int)
+	s.unavailable = make(map[string]bool)
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveBatch))
+	s.client = NewClient(WithBaseURL(s.server.URL), WithHTTPClient(s.server.Client()))
+}
+
+func (s *FleetTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+// serveBatch answers batch requests with the odometer of the vehicle, the access token of vehicle "id" is "token-id".
+func (s *FleetTestSuite) serveBatch(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.URL.Path, "/")
+	id := parts[3]
+
+	s.mu.Lock()
+	s.running++
+	if s.running > s.maxRunning {
+		s.maxRunning = s.running
+	}
+	s.requests[id]++
+	unavailable := s.unavailable[id]
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running--
+		s.mu.Unlock()
+	}()
+	time.Sleep(s.delay)
+
+	if r.Header.Get("Authorization") != "Bearer token-"+id || r.URL.Path != "/v2.0/vehicles/"+id+"/batch" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if unavailable {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"type": "VEHICLE_STATE", "description": "Vehicle is asleep."})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"responses": []map[string]interface{}{
+			{
+				"path":    "/odometer",
+				"code":    200,
+				"headers": map[string]string{"sc-unit-system": "metric"},
+				"body":    map[string]interface{}{"distance": 1000},
+			},
+		},
+	})
+}
+
+func (s *FleetTestSuite) vehicles(ids ...string) []FleetVehicle {
+	var vehicles []FleetVehicle
+	for _, id := range ids {
+		vehicles = append(vehicles, FleetVehicle{ID: id, TokenSource: StaticTokenSource("token-" + id)})
+	}
+	return vehicles
+}
+
+func (s *FleetTestSuite) collect(results <-chan *FleetResult) map[string]*FleetResult {
+	byVehicle := make(map[string]*FleetResult)
+	for result := range results {
+		byVehicle[result.VehicleID] = result
+	}
+	return byVehicle
+}
+
+func (s *FleetTestSuite) TestBatch() {
+	s.unavailable["v3"] = true
+	fleet := s.client.NewFleet(&FleetParams{Vehicles: s.vehicles("v1", "v2", "v3")})
+
+	results := s.collect(fleet.Batch(context.Background(), OdometerPath))
+
+	assert.Len(s.T(), results, 3)
+	assert.Nil(s.T(), results["v1"].Err)
+	assert.Equal(s.T(), 1000.0, results["v1"].Value.(*Data).Odometer.Distance.Value)
+	assert.Equal(s.T(), Metric, results["v2"].Value.(*Data).Odometer.Distance.Units)
+	assert.Equal(s.T(), "VEHICLE_STATE", results["v3"].Err.(*Error).Type)
+}
+
+func (s *FleetTestSuite) TestWorkers() {
+	s.delay = 20 * time.Millisecond
+	fleet := s.client.NewFleet(&FleetParams{
+		Vehicles: s.vehicles("v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8"),
+		Workers:  3,
+	})
+
+	results := s.collect(fleet.Batch(context.Background(), OdometerPath))
+
+	assert.Len(s.T(), results, 8)
+	assert.Equal(s.T(), 3, s.maxRunning)
+}
+
+func (s *FleetTestSuite) TestVehicleRateLimit() {
+	fleet := s.client.NewFleet(&FleetParams{
+		Vehicles:         s.vehicles("v1", "v2"),
+		VehicleRateLimit: 20,
+	})
+	start := time.Now()
+
+	// Each vehicle sends 3 requests, the second and third wait 50ms each.
+	results := s.collect(fleet.Do(context.Background(), func(ctx context.Context, v Vehicle) (interface{}, error) {
+		for i := 0; i < 3; i++ {
+			if _, err := v.Batch(ctx, OdometerPath); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}))
+
+	assert.Len(s.T(), results, 2)
+	assert.True(s.T(), time.Since(start) >= 90*time.Millisecond)
+	assert.True(s.T(), time.Since(start) < 500*time.Millisecond)
+	assert.Equal(s.T(), 3, s.requests["v1"])
+}
+
+func (s *FleetTestSuite) TestGlobalRateLimit() {
+	var ids []string
+	for i := 0; i < 22; i++ {
+		ids = append(ids, fmt.Sprintf("v%d", i))
+	}
+	fleet := s.client.NewFleet(&FleetParams{
+		Vehicles:  s.vehicles(ids...),
+		Workers:   22,
+		RateLimit: 20,
+	})
+	start := time.Now()
+
+	// The first 20 requests are sent at once, the last 2 wait 50ms each.
+	results := s.collect(fleet.Batch(context.Background(), OdometerPath))
+
+	assert.Len(s.T(), results, 22)
+	assert.True(s.T(), time.Since(start) >= 90*time.Millisecond)
+}
+
+func (s *FleetTestSuite) TestReplacesClientRateLimit() {
+	client := NewClient(
+		WithBaseURL(s.server.URL),
+		WithHTTPClient(s.server.Client()),
+		WithRateLimit(&RateLimitParams{ApplicationRate: 1, ApplicationBurst: 1}),
+	)
+	fleet := client.NewFleet(&FleetParams{Vehicles: s.vehicles("v1", "v2", "v3"), RateLimit: 100})
+	start := time.Now()
+
+	// The client would send a request per second, the fleet sends them at once.
+	results := s.collect(fleet.Batch(context.Background(), OdometerPath))
+
+	assert.Len(s.T(), results, 3)
+	assert.True(s.T(), time.Since(start) < 500*time.Millisecond)
+}
+
+func (s *FleetTestSuite) TestTokenSourceError() {
+	failure := errors.New("refresh token expired")
+	fleet := s.client.NewFleet(&FleetParams{Vehicles: []FleetVehicle{{
+		ID: "v1",
+		TokenSource: TokenSourceFunc(func(context.Context) (string, error) {
+			return "", failure
+		}),
+	}}})
+
+	results := s.collect(fleet.Batch(context.Background(), OdometerPath))
+
+	assert.Equal(s.T(), failure, results["v1"].Err)
+	assert.Equal(s.T(), 0, s.requests["v1"])
+}
+
+func (s *FleetTestSuite) TestCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	fleet := s.client.NewFleet(&FleetParams{
+		Vehicles: s.vehicles("v1", "v2", "v3", "v4", "v5", "v6"),
+		Workers:  1,
+	})
+
+	results := fleet.Do(ctx, func(ctx context.Context, v Vehicle) (interface{}, error) {
+		return v.Lock(ctx)
+	})
+	first := <-results
+	cancel()
+	rest := s.collect(results)
+
+	assert.NotNil(s.T(), first)
+	assert.True(s.T(), len(rest) <= 1)
+}
+
+func TestFleetTestSuite(t *testing.T) {
+	suite.Run(t, new(FleetTestSuite))
+}
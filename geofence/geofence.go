@@ -0,0 +1,169 @@
+// Package geofence evaluates Smartcar Location readings against circular and polygonal fences, and reports when
+// vehicles enter, exit or dwell in them.
+package geofence
+
+import (
+	"sync"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// EventType is the type of an Event.
+type EventType string
+
+// EventType constants
+const (
+	Enter EventType = "enter"
+	Exit  EventType = "exit"
+	Dwell EventType = "dwell"
+)
+
+// Fence is a named Shape. Properties are free form, i.e. the properties of a GeoJSON feature.
+type Fence struct {
+	ID         string
+	Properties map[string]interface{}
+	Shape      Shape
+}
+
+// Event is reported when a vehicle enters, exits or dwells in a Fence.
+type Event struct {
+	Type      EventType
+	VehicleID string
+	Fence     *Fence
+	Location  *smartcar.Location
+	// Time is the time of the reading, its DataAge when Smartcar returned one.
+	Time time.Time
+}
+
+// EvaluatorParams is a param in geofence.NewEvaluator
+type EvaluatorParams struct {
+	Fences []*Fence
+	// Hysteresis is how far, in meters, a vehicle needs to be outside of a fence before it exits it, so GPS jitter
+	// around the boundary does not report an exit and an enter on every reading.
+	Hysteresis float64
+	// DwellTime is how long a vehicle needs to stay inside a fence before a Dwell event is reported, once per visit.
+	// Dwell events are disabled when it is 0.
+	DwellTime time.Duration
+	// Now is the time of readings without a DataAge. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Evaluator keeps track of which fences every vehicle is in. It is safe for concurrent use.
+type Evaluator interface {
+	// Evaluate updates the state of the vehicle with a new reading and returns the resulting events. The first
+	// reading of a vehicle only records which fences it is in. Readings older than the previous one are ignored.
+	Evaluate(vehicleID string, location *smartcar.Location) []*Event
+	// Inside returns the fences the vehicle is currently in.
+	Inside(vehicleID string) []*Fence
+	// Forget drops the state of a vehicle.
+	Forget(vehicleID string)
+}
+
+// evaluator implements the Evaluator interface.
+type evaluator struct {
+	fences     []*Fence
+	hysteresis float64
+	dwellTime  time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	vehicles map[string]*vehicleState
+}
+
+type vehicleState struct {
+	last   time.Time
+	fences map[*Fence]*fenceState
+}
+
+type fenceState struct {
+	inside  bool
+	since   time.Time
+	dwelled bool
+}
+
+// NewEvaluator creates an Evaluator for the given fences.
+func NewEvaluator(params *EvaluatorParams) Evaluator {
+	now := params.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &evaluator{
+		fences:     params.Fences,
+		hysteresis: params.Hysteresis,
+		dwellTime:  params.DwellTime,
+		now:        now,
+		vehicles:   make(map[string]*vehicleState),
+	}
+}
+
+// Evaluate implements Evaluator.
+func (e *evaluator) Evaluate(vehicleID string, location *smartcar.Location) []*Event {
+	at := location.DataAge
+	if at.IsZero() {
+		at = e.now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	vehicle, seen := e.vehicles[vehicleID]
+	if !seen {
+		vehicle = &vehicleState{fences: make(map[*Fence]*fenceState)}
+		e.vehicles[vehicleID] = vehicle
+	} else if at.Before(vehicle.last) {
+		return nil
+	}
+	vehicle.last = at
+
+	var events []*Event
+	newEvent := func(eventType EventType, fence *Fence) {
+		events = append(events, &Event{Type: eventType, VehicleID: vehicleID, Fence: fence, Location: location, Time: at})
+	}
+	for _, fence := range e.fences {
+		distance := fence.Shape.Distance(location.Latitude, location.Longitude)
+		state, ok := vehicle.fences[fence]
+		if !ok {
+			vehicle.fences[fence] = &fenceState{inside: distance <= 0, since: at}
+			continue
+		}
+
+		switch {
+		case !state.inside && distance <= 0:
+			*state = fenceState{inside: true, since: at}
+			newEvent(Enter, fence)
+		case state.inside && distance > e.hysteresis:
+			*state = fenceState{inside: false, since: at}
+			newEvent(Exit, fence)
+		case state.inside && !state.dwelled && e.dwellTime > 0 && at.Sub(state.since) >= e.dwellTime:
+			state.dwelled = true
+			newEvent(Dwell, fence)
+		}
+	}
+	return events
+}
+
+// Inside implements Evaluator.
+func (e *evaluator) Inside(vehicleID string) []*Fence {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	vehicle, ok := e.vehicles[vehicleID]
+	if !ok {
+		return nil
+	}
+	var fences []*Fence
+	for _, fence := range e.fences {
+		if vehicle.fences[fence].inside {
+			fences = append(fences, fence)
+		}
+	}
+	return fences
+}
+
+// Forget implements Evaluator.
+func (e *evaluator) Forget(vehicleID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.vehicles, vehicleID)
+}
@@ -0,0 +1,150 @@
+package geofence
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type EvaluatorTestSuite struct {
+	suite.Suite
+	fence     *Fence
+	evaluator Evaluator
+	start     time.Time
+}
+
+func (s *EvaluatorTestSuite) SetupTest() {
+	s.start = time.Date(2020, 1, 1, 22, 0, 0, 0, time.UTC)
+	s.fence = &Fence{ID: "yard", Shape: yard}
+	s.evaluator = NewEvaluator(&EvaluatorParams{
+		Fences:     []*Fence{s.fence},
+		Hysteresis: 20,
+		DwellTime:  10 * time.Minute,
+	})
+}
+
+// at returns a reading latitude degrees north of the center of the yard, minutes after the start of the test.
+func (s *EvaluatorTestSuite) at(minutes int, latitude float64) *smartcar.Location {
+	return &smartcar.Location{
+		Latitude:        latitude,
+		ResponseHeaders: smartcar.ResponseHeaders{DataAge: s.start.Add(time.Duration(minutes) * time.Minute)},
+	}
+}
+
+func (s *EvaluatorTestSuite) types(events []*Event) []EventType {
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func (s *EvaluatorTestSuite) TestBaseline() {
+	events := s.evaluator.Evaluate("van", s.at(0, 0))
+
+	assert.Empty(s.T(), events)
+	assert.Equal(s.T(), []*Fence{s.fence}, s.evaluator.Inside("van"))
+	assert.Nil(s.T(), s.evaluator.Inside("truck"))
+}
+
+func (s *EvaluatorTestSuite) TestExitAndEnter() {
+	s.evaluator.Evaluate("van", s.at(0, 0))
+
+	exit := s.evaluator.Evaluate("van", s.at(1, 0.002))
+	enter := s.evaluator.Evaluate("van", s.at(2, 0))
+
+	assert.Equal(s.T(), []EventType{Exit}, s.types(exit))
+	assert.Equal(s.T(), "van", exit[0].VehicleID)
+	assert.Equal(s.T(), s.fence, exit[0].Fence)
+	assert.Equal(s.T(), s.start.Add(time.Minute), exit[0].Time)
+	assert.Equal(s.T(), 0.002, exit[0].Location.Latitude)
+	assert.Equal(s.T(), []EventType{Enter}, s.types(enter))
+}
+
+func (s *EvaluatorTestSuite) TestHysteresis() {
+	s.evaluator.Evaluate("van", s.at(0, 0.0009))
+
+	// The yard ends at 0.001, about 11 meters outside of it is within the hysteresis.
+	jitter := s.evaluator.Evaluate("van", s.at(1, 0.0011))
+	back := s.evaluator.Evaluate("van", s.at(2, 0.0009))
+	exit := s.evaluator.Evaluate("van", s.at(3, 0.0013))
+	// Entering again requires being inside of the fence.
+	outside := s.evaluator.Evaluate("van", s.at(4, 0.0011))
+
+	assert.Empty(s.T(), jitter)
+	assert.Empty(s.T(), back)
+	assert.Equal(s.T(), []EventType{Exit}, s.types(exit))
+	assert.Empty(s.T(), outside)
+}
+
+func (s *EvaluatorTestSuite) TestDwell() {
+	s.evaluator.Evaluate("van", s.at(0, 0.002))
+	enter := s.evaluator.Evaluate("van", s.at(1, 0))
+
+	early := s.evaluator.Evaluate("van", s.at(5, 0))
+	dwell := s.evaluator.Evaluate("van", s.at(11, 0))
+	again := s.evaluator.Evaluate("van", s.at(30, 0))
+
+	assert.Equal(s.T(), []EventType{Enter}, s.types(enter))
+	assert.Empty(s.T(), early)
+	assert.Equal(s.T(), []EventType{Dwell}, s.types(dwell))
+	assert.Empty(s.T(), again)
+}
+
+func (s *EvaluatorTestSuite) TestOutOfOrderReadings() {
+	s.evaluator.Evaluate("van", s.at(5, 0))
+
+	events := s.evaluator.Evaluate("van", s.at(1, 0.002))
+
+	assert.Empty(s.T(), events)
+	assert.Equal(s.T(), []*Fence{s.fence}, s.evaluator.Inside("van"))
+}
+
+func (s *EvaluatorTestSuite) TestReadingsWithoutDataAge() {
+	now := s.start
+	evaluator := NewEvaluator(&EvaluatorParams{
+		Fences: []*Fence{s.fence},
+		Now:    func() time.Time { return now },
+	})
+	evaluator.Evaluate("van", &smartcar.Location{})
+	now = now.Add(time.Minute)
+
+	events := evaluator.Evaluate("van", &smartcar.Location{Latitude: 0.002})
+
+	assert.Equal(s.T(), []EventType{Exit}, s.types(events))
+	assert.Equal(s.T(), now, events[0].Time)
+}
+
+func (s *EvaluatorTestSuite) TestForget() {
+	s.evaluator.Evaluate("van", s.at(0, 0))
+
+	s.evaluator.Forget("van")
+	events := s.evaluator.Evaluate("van", s.at(1, 0.002))
+
+	assert.Empty(s.T(), events)
+	assert.Empty(s.T(), s.evaluator.Inside("van"))
+}
+
+func (s *EvaluatorTestSuite) TestConcurrentVehicles() {
+	var wg sync.WaitGroup
+	for _, id := range []string{"van", "truck", "car"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.evaluator.Evaluate(id, s.at(i, float64(i%2)*0.002))
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	assert.Empty(s.T(), s.evaluator.Inside("car"))
+}
+
+func TestEvaluatorTestSuite(t *testing.T) {
+	suite.Run(t, new(EvaluatorTestSuite))
+}
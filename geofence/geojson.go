@@ -0,0 +1,141 @@
+package geofence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// RadiusProperty is the property of a GeoJSON Point feature that holds the radius of a circular fence, in meters.
+const RadiusProperty = "radius"
+
+// geoJSON holds the members of GeoJSON objects that are used by ParseGeoJSON.
+type geoJSON struct {
+	Type        string                 `json:"type"`
+	ID          interface{}            `json:"id"`
+	Properties  map[string]interface{} `json:"properties"`
+	Geometry    *geoJSON               `json:"geometry"`
+	Features    []*geoJSON             `json:"features"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+}
+
+// ParseGeoJSON reads fences from a GeoJSON FeatureCollection, Feature or geometry. Polygon and MultiPolygon
+// geometries are supported, as well as Point features with a "radius" property which are read as a Circle.
+// The ID of a fence is the id of its feature, or its "name" property.
+func ParseGeoJSON(data []byte) ([]*Fence, error) {
+	object := new(geoJSON)
+	if err := json.Unmarshal(data, object); err != nil {
+		return nil, err
+	}
+
+	switch object.Type {
+	case "FeatureCollection":
+		fences := make([]*Fence, 0, len(object.Features))
+		for i, feature := range object.Features {
+			fence, err := parseFeature(feature)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %s", i, err)
+			}
+			fences = append(fences, fence)
+		}
+		return fences, nil
+	case "Feature":
+		fence, err := parseFeature(object)
+		if err != nil {
+			return nil, err
+		}
+		return []*Fence{fence}, nil
+	default:
+		shape, err := parseGeometry(object, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []*Fence{{Shape: shape}}, nil
+	}
+}
+
+// parseFeature reads a fence from a Feature.
+func parseFeature(feature *geoJSON) (*Fence, error) {
+	if feature.Type != "Feature" {
+		return nil, fmt.Errorf("unexpected type %q", feature.Type)
+	}
+	if feature.Geometry == nil {
+		return nil, errors.New("missing geometry")
+	}
+
+	shape, err := parseGeometry(feature.Geometry, feature.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	fence := &Fence{Properties: feature.Properties, Shape: shape}
+	if feature.ID != nil {
+		fence.ID = fmt.Sprint(feature.ID)
+	} else if name, ok := feature.Properties["name"].(string); ok {
+		fence.ID = name
+	}
+	return fence, nil
+}
+
+// parseGeometry reads the shape of a geometry, properties are the properties of its feature.
+func parseGeometry(geometry *geoJSON, properties map[string]interface{}) (Shape, error) {
+	switch geometry.Type {
+	case "Point":
+		var position []float64
+		if err := json.Unmarshal(geometry.Coordinates, &position); err != nil || len(position) < 2 {
+			return nil, errors.New("invalid Point coordinates")
+		}
+		radius, ok := properties[RadiusProperty].(float64)
+		if !ok || radius <= 0 {
+			return nil, fmt.Errorf("Point features need a positive %q property", RadiusProperty)
+		}
+		return &Circle{Center: Point{Latitude: position[1], Longitude: position[0]}, Radius: radius}, nil
+	case "Polygon":
+		var coordinates [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &coordinates); err != nil {
+			return nil, errors.New("invalid Polygon coordinates")
+		}
+		return parsePolygon(coordinates)
+	case "MultiPolygon":
+		var coordinates [][][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &coordinates); err != nil {
+			return nil, errors.New("invalid MultiPolygon coordinates")
+		}
+		multiPolygon := &MultiPolygon{}
+		for _, polygonCoordinates := range coordinates {
+			polygon, err := parsePolygon(polygonCoordinates)
+			if err != nil {
+				return nil, err
+			}
+			multiPolygon.Polygons = append(multiPolygon.Polygons, polygon)
+		}
+		return multiPolygon, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry %q", geometry.Type)
+	}
+}
+
+// parsePolygon reads the rings of a Polygon, GeoJSON positions are [longitude, latitude].
+func parsePolygon(coordinates [][][]float64) (*Polygon, error) {
+	if len(coordinates) == 0 {
+		return nil, errors.New("Polygon without rings")
+	}
+	polygon := &Polygon{}
+	for _, ringCoordinates := range coordinates {
+		ring := make([]Point, 0, len(ringCoordinates))
+		for _, position := range ringCoordinates {
+			if len(position) < 2 {
+				return nil, errors.New("invalid Polygon position")
+			}
+			ring = append(ring, Point{Latitude: position[1], Longitude: position[0]})
+		}
+		if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+			ring = ring[:len(ring)-1]
+		}
+		if len(ring) < 3 {
+			return nil, errors.New("Polygon rings need at least 3 positions")
+		}
+		polygon.Rings = append(polygon.Rings, ring)
+	}
+	return polygon, nil
+}
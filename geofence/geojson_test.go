@@ -0,0 +1,91 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGeoJSON(t *testing.T) {
+	// Arrange
+	data := []byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"id": "yard",
+				"properties": {"afterHours": true},
+				"geometry": {
+					"type": "Polygon",
+					"coordinates": [[[-0.001, -0.001], [0.001, -0.001], [0.001, 0.001], [-0.001, 0.001], [-0.001, -0.001]]]
+				}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "charger", "radius": 25},
+				"geometry": {"type": "Point", "coordinates": [2.3522, 48.8566]}
+			},
+			{
+				"type": "Feature",
+				"id": 42,
+				"geometry": {
+					"type": "MultiPolygon",
+					"coordinates": [[[[0, 0], [1, 0], [1, 1]]], [[[2, 2], [3, 2], [3, 3]]]]
+				}
+			}
+		]
+	}`)
+
+	// Act
+	fences, err := ParseGeoJSON(data)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, fences, 3)
+
+	assert.Equal(t, "yard", fences[0].ID)
+	assert.Equal(t, true, fences[0].Properties["afterHours"])
+	assert.Equal(t, &Polygon{Rings: [][]Point{{
+		{Latitude: -0.001, Longitude: -0.001},
+		{Latitude: -0.001, Longitude: 0.001},
+		{Latitude: 0.001, Longitude: 0.001},
+		{Latitude: 0.001, Longitude: -0.001},
+	}}}, fences[0].Shape)
+
+	assert.Equal(t, "charger", fences[1].ID)
+	assert.Equal(t, &Circle{Center: Point{Latitude: 48.8566, Longitude: 2.3522}, Radius: 25}, fences[1].Shape)
+
+	assert.Equal(t, "42", fences[2].ID)
+	assert.Len(t, fences[2].Shape.(*MultiPolygon).Polygons, 2)
+}
+
+func TestParseGeoJSONGeometry(t *testing.T) {
+	// Arrange
+	data := []byte(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1]]]}`)
+
+	// Act
+	fences, err := ParseGeoJSON(data)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, fences, 1)
+	assert.Equal(t, "", fences[0].ID)
+	assert.Len(t, fences[0].Shape.(*Polygon).Rings[0], 3)
+}
+
+func TestParseGeoJSONErrors(t *testing.T) {
+	tests := map[string]string{
+		"json":        `{`,
+		"geometry":    `{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}`,
+		"radius":      `{"type": "Feature", "geometry": {"type": "Point", "coordinates": [0, 0]}}`,
+		"ring":        `{"type": "Polygon", "coordinates": [[[0, 0], [1, 1], [0, 0]]]}`,
+		"no geometry": `{"type": "FeatureCollection", "features": [{"type": "Feature"}]}`,
+	}
+
+	for name, data := range tests {
+		fences, err := ParseGeoJSON([]byte(data))
+
+		assert.NotNil(t, err, name)
+		assert.Nil(t, fences, name)
+	}
+}
@@ -0,0 +1,120 @@
+package geofence
+
+import (
+	"math"
+
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// Shape is the area covered by a Fence.
+type Shape interface {
+	// Distance returns the distance in meters between a point and the boundary of the shape. It is negative
+	// when the point is inside the shape.
+	Distance(latitude, longitude float64) float64
+}
+
+// Point is a position in degrees.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Circle is a Shape of Radius meters around its center.
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+// Polygon is a Shape bounded by its first ring. The following rings are holes. Rings do not need to be closed.
+type Polygon struct {
+	Rings [][]Point
+}
+
+// MultiPolygon is a Shape made of several polygons.
+type MultiPolygon struct {
+	Polygons []*Polygon
+}
+
+// Distance implements Shape.
+func (c *Circle) Distance(latitude, longitude float64) float64 {
+	center := &smartcar.Location{Latitude: c.Center.Latitude, Longitude: c.Center.Longitude}
+	return center.DistanceTo(&smartcar.Location{Latitude: latitude, Longitude: longitude}) - c.Radius
+}
+
+// Distance implements Shape. The boundary is approximated with an equirectangular projection around the point,
+// which is accurate for fences up to a few dozen kilometers.
+func (p *Polygon) Distance(latitude, longitude float64) float64 {
+	inside := false
+	nearest := math.Inf(1)
+	for i, ring := range p.Rings {
+		if len(ring) < 3 {
+			continue
+		}
+		if contains(ring, latitude, longitude) {
+			// Inside the outer ring, unless inside one of the holes.
+			inside = i == 0
+		}
+		nearest = math.Min(nearest, distanceToRing(ring, latitude, longitude))
+		if i == 0 && !inside {
+			// A point outside of the outer ring can't be closer to a hole.
+			break
+		}
+	}
+	if inside {
+		return -nearest
+	}
+	return nearest
+}
+
+// Distance implements Shape.
+func (m *MultiPolygon) Distance(latitude, longitude float64) float64 {
+	distance := math.Inf(1)
+	for _, polygon := range m.Polygons {
+		distance = math.Min(distance, polygon.Distance(latitude, longitude))
+	}
+	return distance
+}
+
+// contains reports whether the point is inside ring, using the even-odd rule.
+func contains(ring []Point, latitude, longitude float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Latitude > latitude) != (b.Latitude > latitude) &&
+			longitude < (b.Longitude-a.Longitude)*(latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// distanceToRing returns the distance in meters between the point and the nearest edge of ring.
+func distanceToRing(ring []Point, latitude, longitude float64) float64 {
+	// Project the ring on a plane, in meters, with the point at the origin.
+	scale := earthRadius * math.Pi / 180
+	cos := math.Cos(latitude * math.Pi / 180)
+	project := func(p Point) (float64, float64) {
+		return (p.Longitude - longitude) * cos * scale, (p.Latitude - latitude) * scale
+	}
+
+	nearest := math.Inf(1)
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		ax, ay := project(ring[j])
+		bx, by := project(ring[i])
+		nearest = math.Min(nearest, distanceToSegment(ax, ay, bx, by))
+	}
+	return nearest
+}
+
+// distanceToSegment returns the distance between the origin and the segment from a to b.
+func distanceToSegment(ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/length))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
@@ -0,0 +1,79 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// yard is a square of about 222m by 222m around (0, 0).
+var yard = &Polygon{Rings: [][]Point{{
+	{Latitude: -0.001, Longitude: -0.001},
+	{Latitude: -0.001, Longitude: 0.001},
+	{Latitude: 0.001, Longitude: 0.001},
+	{Latitude: 0.001, Longitude: -0.001},
+}}}
+
+func TestCircleDistance(t *testing.T) {
+	// Arrange
+	circle := &Circle{Center: Point{Latitude: 0, Longitude: 0}, Radius: 100}
+
+	// Act
+	center := circle.Distance(0, 0)
+	outside := circle.Distance(0.001, 0)
+
+	// Assert
+	assert.Equal(t, -100.0, center)
+	assert.InDelta(t, 11.2, outside, 0.1)
+}
+
+func TestPolygonDistance(t *testing.T) {
+	// Act
+	center := yard.Distance(0, 0)
+	edge := yard.Distance(0.0009, 0)
+	outside := yard.Distance(0.002, 0)
+	corner := yard.Distance(0.002, 0.002)
+
+	// Assert
+	assert.InDelta(t, -111.2, center, 0.1)
+	assert.InDelta(t, -11.1, edge, 0.1)
+	assert.InDelta(t, 111.2, outside, 0.1)
+	assert.InDelta(t, 157.2, corner, 0.1)
+}
+
+func TestPolygonWithHole(t *testing.T) {
+	// Arrange
+	polygon := &Polygon{Rings: [][]Point{
+		yard.Rings[0],
+		{
+			{Latitude: -0.0005, Longitude: -0.0005},
+			{Latitude: -0.0005, Longitude: 0.0005},
+			{Latitude: 0.0005, Longitude: 0.0005},
+			{Latitude: 0.0005, Longitude: -0.0005},
+		},
+	}}
+
+	// Act
+	hole := polygon.Distance(0, 0)
+	ring := polygon.Distance(0.00075, 0)
+
+	// Assert
+	assert.InDelta(t, 55.6, hole, 0.1)
+	assert.InDelta(t, -27.8, ring, 0.1)
+}
+
+func TestMultiPolygonDistance(t *testing.T) {
+	// Arrange
+	east := &Polygon{Rings: [][]Point{{
+		{Latitude: -0.001, Longitude: 0.01},
+		{Latitude: -0.001, Longitude: 0.012},
+		{Latitude: 0.001, Longitude: 0.012},
+		{Latitude: 0.001, Longitude: 0.01},
+	}}}
+	multiPolygon := &MultiPolygon{Polygons: []*Polygon{yard, east}}
+
+	// Act & Assert
+	assert.Less(t, multiPolygon.Distance(0, 0), 0.0)
+	assert.Less(t, multiPolygon.Distance(0, 0.011), 0.0)
+	assert.Greater(t, multiPolygon.Distance(0, 0.005), 0.0)
+}
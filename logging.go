@@ -0,0 +1,147 @@
+package smartcar
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LoggerParams is a param in smartcar.WithLogger
+type LoggerParams struct {
+	// Logger receives the logs, it defaults to slog.Default().
+	Logger *slog.Logger
+	// Level is the level of the logs of successful requests, failed requests are logged as errors.
+	Level slog.Level
+	// Headers and Bodies add the headers and bodies of the requests and responses to the logs.
+	Headers bool
+	Bodies  bool
+	// Unredacted logs tokens, credentials, authorization codes and VINs, which are redacted by default.
+	Unredacted bool
+}
+
+// WithLogger logs every request of the client, with its method, URL, status, latency, Sc-Request-Id and retry
+// attempt. Like other middlewares, it only sees the requests that reach it, after the middlewares added before it.
+func WithLogger(params *LoggerParams) ClientOption {
+	return WithMiddleware(newLogger(params))
+}
+
+// newLogger returns a Middleware logging requests.
+func newLogger(params *LoggerParams) Middleware {
+	p := *params
+	if p.Logger == nil {
+		p.Logger = slog.Default()
+	}
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(r *Request) (*Response, error) {
+			start := time.Now()
+			res, err := next.RoundTrip(r)
+			p.log(r, res, err, time.Since(start))
+			return res, err
+		})
+	}
+}
+
+// log logs a request and its response.
+func (p *LoggerParams) log(r *Request, res *Response, err error, latency time.Duration) {
+	level := p.Level
+	if err != nil {
+		level = slog.LevelError
+	}
+	ctx := r.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !p.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("url", p.redactURL(r.URL)),
+		slog.Duration("latency", latency),
+		slog.Int("attempt", r.Attempt),
+	}
+	if r.Path != "" {
+		attrs = append(attrs, slog.String("path", string(r.Path)), slog.String("vehicle_id", r.VehicleID))
+	}
+	requestID := ""
+	if scErr, ok := err.(*Error); ok {
+		requestID = scErr.RequestID
+		attrs = append(attrs, slog.String("error_type", scErr.Type), slog.String("error_code", scErr.Code))
+	}
+	if res != nil {
+		attrs = append(attrs, slog.Int("status", res.StatusCode))
+		requestID = res.Header.Get("Sc-Request-Id")
+	}
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", p.redactError(err)))
+	}
+	if p.Headers {
+		attrs = append(attrs, slog.Any("request_headers", p.redactHeader(r.Header)))
+		if res != nil {
+			attrs = append(attrs, slog.Any("response_headers", p.redactHeader(res.Header)))
+		}
+	}
+	if p.Bodies {
+		if r.Body != nil {
+			attrs = append(attrs, slog.String("request_body", p.redact(string(r.Body), isJSON(r.Header.Get("Content-Type"), r.Body))))
+		}
+		if res != nil {
+			attrs = append(attrs, slog.String("response_body", p.redact(string(res.Body), isJSON(res.Header.Get("Content-Type"), res.Body))))
+		}
+	}
+	p.Logger.LogAttrs(ctx, level, "smartcar request", attrs...)
+}
+
+// redact redacts s, unless the logs are Unredacted.
+func (p *LoggerParams) redact(s string, json bool) string {
+	if p.Unredacted {
+		return s
+	}
+	return redact(s, json)
+}
+
+// redactURL redacts rawURL, unless the logs are Unredacted.
+func (p *LoggerParams) redactURL(rawURL string) string {
+	if p.Unredacted {
+		return rawURL
+	}
+	return redactURL(rawURL)
+}
+
+// redactError returns the message of err, with the URL of the errors of the http.Client redacted.
+func (p *LoggerParams) redactError(err error) string {
+	if urlErr, ok := err.(*url.Error); ok {
+		redactedErr := *urlErr
+		redactedErr.URL = p.redactURL(urlErr.URL)
+		err = &redactedErr
+	}
+	return p.redact(err.Error(), false)
+}
+
+// redactHeader returns the headers of a request or response, with their credentials redacted.
+func (p *LoggerParams) redactHeader(header http.Header) slog.Value {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	attrs := make([]slog.Attr, 0, len(header))
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if !p.Unredacted && http.CanonicalHeaderKey(name) == "Authorization" {
+			// Keep the scheme, i.e. Bearer or Basic.
+			scheme := strings.SplitN(value, " ", 2)[0]
+			value = scheme + " " + redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,113 @@
+package smartcar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeLogs returns the JSON records written in buf.
+func decodeLogs(buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]interface{}{}
+		json.Unmarshal([]byte(line), &record)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogger(t *testing.T) {
+	// Arrange
+	server := newFixtureServer()
+	defer server.Close()
+	buf := new(bytes.Buffer)
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithLogger(&LoggerParams{Logger: slog.New(slog.NewJSONHandler(buf, nil)), Headers: true, Bodies: true}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "secret-token"})
+	auth := client.NewAuth(&AuthParams{ClientID: "client-id", ClientSecret: "client-secret"})
+
+	// Act
+	vehicle.GetVIN(context.Background())
+	vehicle.GetOdometer(context.Background())
+	auth.ExchangeCode(context.Background(), &ExchangeCodeParams{Code: "secret-code"})
+	records := decodeLogs(buf)
+
+	// Assert
+	assert.Len(t, records, 3)
+	for _, secret := range []string{"secret-token", "secret-code", "secret-access", "secret-refresh", "client-secret", "5YJ3E1EA1JF000001"} {
+		assert.NotContains(t, buf.String(), secret)
+	}
+
+	vin := records[0]
+	assert.Equal(t, "INFO", vin["level"])
+	assert.Equal(t, "smartcar request", vin["msg"])
+	assert.Equal(t, http.MethodGet, vin["method"])
+	assert.Equal(t, server.URL+"/v2.0/vehicles/vehicle-id/vin", vin["url"])
+	assert.Equal(t, "/vin", vin["path"])
+	assert.Equal(t, "vehicle-id", vin["vehicle_id"])
+	assert.Equal(t, 200.0, vin["status"])
+	assert.Equal(t, "request-id", vin["request_id"])
+	assert.Equal(t, 0.0, vin["attempt"])
+	assert.Contains(t, vin, "latency")
+	assert.Equal(t, "Bearer REDACTED", vin["request_headers"].(map[string]interface{})["Authorization"])
+	assert.Equal(t, `{"vin":"REDACTED"}`, vin["response_body"])
+
+	odometer := records[1]
+	assert.Equal(t, "ERROR", odometer["level"])
+	assert.Equal(t, 409.0, odometer["status"])
+	assert.Equal(t, "VEHICLE_STATE", odometer["error_type"])
+	assert.Equal(t, "ASLEEP", odometer["error_code"])
+	assert.Equal(t, "Conflict: VEHICLE_STATE - Vehicle REDACTED is asleep.", odometer["error"])
+
+	token := records[2]
+	assert.Equal(t, "Basic REDACTED", token["request_headers"].(map[string]interface{})["Authorization"])
+	assert.Contains(t, token["request_body"], "code=REDACTED")
+	assert.NotContains(t, token, "path")
+}
+
+func TestLoggerLevel(t *testing.T) {
+	// Arrange
+	server := newFixtureServer()
+	defer server.Close()
+	buf := new(bytes.Buffer)
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithLogger(&LoggerParams{Logger: slog.New(handler), Level: slog.LevelDebug, Unredacted: true}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "secret-token"})
+
+	// Act
+	vehicle.GetVIN(context.Background())
+	vehicle.GetOdometer(context.Background())
+	records := decodeLogs(buf)
+
+	// Assert
+	// Only the failed request is logged, unredacted and without its headers and body.
+	assert.Len(t, records, 1)
+	assert.Contains(t, records[0]["error"], "5YJ3E1EA1JF000001")
+	assert.NotContains(t, records[0], "request_headers")
+	assert.NotContains(t, records[0], "response_body")
+}
+
+func TestLoggerRedactURL(t *testing.T) {
+	// Arrange
+	params := &LoggerParams{}
+
+	// Act
+	u := params.redactURL("https://api.smartcar.com/v2.0/compatibility?vin=5YJ3E1EA1JF000001&scope=read_vin")
+
+	// Assert
+	assert.Equal(t, "https://api.smartcar.com/v2.0/compatibility?vin=REDACTED&scope=read_vin", u)
+}
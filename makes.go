@@ -0,0 +1,56 @@
+package smartcar
+
+import "sync"
+
+// vehicleMakes remembers the make of the vehicles from the responses of vehicle.GetInfo and vehicle.Batch, so
+// that metrics and circuit breakers can tell makes apart. A client shares one vehicleMakes between its options.
+type vehicleMakes struct {
+	mu    sync.RWMutex
+	makes map[string]string
+}
+
+// newVehicleMakes returns an empty vehicleMakes.
+func newVehicleMakes() *vehicleMakes {
+	return &vehicleMakes{makes: map[string]string{}}
+}
+
+// get returns the make of a vehicle, or "" when it is unknown.
+func (m *vehicleMakes) get(vehicleID string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.makes[vehicleID]
+}
+
+// learn remembers the make of a vehicle when the result of one of its requests holds its Info.
+func (m *vehicleMakes) learn(vehicleID string, result interface{}) {
+	if vehicleID == "" {
+		return
+	}
+	vehicleMake := ""
+	switch result := result.(type) {
+	case *Info:
+		vehicleMake = result.Make
+	case *batchResponse:
+		for _, item := range result.Responses {
+			if Key(item.Path) != InfoPath || item.Code != 200 {
+				continue
+			}
+			body, _ := item.Body.(map[string]interface{})
+			vehicleMake, _ = body["make"].(string)
+		}
+	}
+	if vehicleMake == "" {
+		return
+	}
+	m.mu.Lock()
+	m.makes[vehicleID] = vehicleMake
+	m.mu.Unlock()
+}
+
+// vehicleMakes returns the vehicleMakes of the client.
+func (o *clientOptions) vehicleMakes() *vehicleMakes {
+	if o.makes == nil {
+		o.makes = newVehicleMakes()
+	}
+	return o.makes
+}
@@ -0,0 +1,282 @@
+package smartcar
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Observation is the outcome of a request, or of a path of a batch request, recorded by Metrics.
+type Observation struct {
+	// Path is the endpoint of a vehicle request, i.e. OdometerPath, or the path of the URL of other requests.
+	// Batch is set for the observations of the paths of a batch request, which also has its own observation.
+	Path  Key
+	Batch bool
+	// Make is the make of the vehicle, once vehicle.GetInfo returned it. It is "" until then.
+	Make string
+	// StatusCode is 0 when the request could not be sent. ErrorType and ErrorCode are set for Smartcar errors,
+	// i.e. "VEHICLE_STATE" and "ASLEEP".
+	StatusCode int
+	ErrorType  string
+	ErrorCode  string
+	// Latency is the duration of the request, Attempt counts its retries by WithRateLimit.
+	Latency time.Duration
+	Attempt int
+	// DataAge is how long ago the data of the response was fetched from the vehicle, or 0 when it is unknown.
+	DataAge time.Duration
+}
+
+// Metrics records the observations of the requests of a client, i.e. a MemoryMetrics or the collector of the
+// promsmartcar module for Prometheus.
+type Metrics interface {
+	Observe(*Observation)
+}
+
+// WithMetrics records an observation of every request of the client, and of every path of the requests of
+// vehicle.Batch, in metrics. Observations are split by vehicle make once vehicle.GetInfo was called for a
+// vehicle. Like other middlewares, metrics only see the requests that reach them, after the middlewares added
+// before them.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(o *clientOptions) {
+		o.middlewares = append(o.middlewares, newMetricsMiddleware(metrics, o.vehicleMakes()))
+	}
+}
+
+// newMetricsMiddleware returns a Middleware recording the observations of requests in metrics.
+func newMetricsMiddleware(metrics Metrics, makes *vehicleMakes) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(r *Request) (*Response, error) {
+			start := time.Now()
+			res, err := next.RoundTrip(r)
+			latency := time.Since(start)
+			if res != nil {
+				makes.learn(r.VehicleID, res.Result)
+			}
+
+			o := &Observation{
+				Path:    metricsPath(r),
+				Make:    makes.get(r.VehicleID),
+				Latency: latency,
+				Attempt: r.Attempt,
+			}
+			if res != nil {
+				o.StatusCode = res.StatusCode
+				if dataAge := parseDataAge(res.Header.Get("Sc-Data-Age")); !dataAge.IsZero() {
+					o.DataAge = time.Since(dataAge)
+				}
+			}
+			if scErr, ok := err.(*Error); ok {
+				o.ErrorType, o.ErrorCode = scErr.Type, scErr.Code
+			}
+			metrics.Observe(o)
+
+			if res == nil {
+				return res, err
+			}
+			if batch, ok := res.Result.(*batchResponse); ok {
+				for _, item := range batch.Responses {
+					itemObservation := &Observation{
+						Path:       Key(item.Path),
+						Batch:      true,
+						Make:       o.Make,
+						StatusCode: item.Code,
+						Latency:    latency,
+						Attempt:    r.Attempt,
+					}
+					if dataAge := parseDataAge(item.Headers.DataAge); !dataAge.IsZero() {
+						itemObservation.DataAge = time.Since(dataAge)
+					}
+					if itemErr := batchItemError(item); itemErr != nil {
+						itemObservation.ErrorType, itemObservation.ErrorCode = itemErr.Type, itemErr.Code
+					}
+					metrics.Observe(itemObservation)
+				}
+			}
+			return res, err
+		})
+	}
+}
+
+// metricsPath returns the Path of the Observation of r.
+func metricsPath(r *Request) Key {
+	if r.Path != "" {
+		return r.Path
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return ""
+	}
+	return Key(u.Path)
+}
+
+// Default buckets of the histograms of MemoryMetrics.
+var (
+	// DefaultLatencyBuckets go up to the timeout of the default http.Client.
+	DefaultLatencyBuckets = []time.Duration{
+		50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+		time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+		time.Minute, 2 * time.Minute, defaultHTTPTimeout,
+	}
+	DefaultDataAgeBuckets = []time.Duration{
+		time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour,
+	}
+)
+
+// Histogram counts durations in buckets. Counts[i] is the number of durations up to Buckets[i], the last count
+// being the number of durations above the last bucket.
+type Histogram struct {
+	Buckets []time.Duration
+	Counts  []int
+	Count   int
+	Sum     time.Duration
+}
+
+// newHistogram returns an empty Histogram with buckets.
+func newHistogram(buckets []time.Duration) Histogram {
+	return Histogram{Buckets: buckets, Counts: make([]int, len(buckets)+1)}
+}
+
+// observe adds d to the histogram.
+func (h *Histogram) observe(d time.Duration) {
+	i := sort.Search(len(h.Buckets), func(i int) bool { return d <= h.Buckets[i] })
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+// copy returns a copy of the histogram that does not share its counts.
+func (h Histogram) copy() Histogram {
+	h.Counts = append([]int(nil), h.Counts...)
+	return h
+}
+
+// EndpointMetrics are the metrics of the requests to an endpoint, for vehicles of a make.
+type EndpointMetrics struct {
+	Path  Key
+	Batch bool
+	Make  string
+	// Requests counts every request, including retries and failed requests. Retries counts the retries, and
+	// RateLimited the requests rejected with a 429 Too Many Requests.
+	Requests    int
+	Retries     int
+	RateLimited int
+	// Errors counts the failed requests by Observation.ErrorLabel.
+	Errors map[string]int
+	// Latency is the histogram of the latencies of the requests, and DataAge of the data ages of their responses.
+	Latency Histogram
+	DataAge Histogram
+}
+
+// endpointKey identifies the metrics of an endpoint.
+type endpointKey struct {
+	path        Key
+	batch       bool
+	vehicleMake string
+}
+
+// MemoryMetrics is a Metrics keeping the metrics of every endpoint in memory.
+type MemoryMetrics struct {
+	latencyBuckets, dataAgeBuckets []time.Duration
+
+	mu        sync.Mutex
+	endpoints map[endpointKey]*EndpointMetrics
+}
+
+// NewMemoryMetrics returns an empty MemoryMetrics, with the default buckets.
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		latencyBuckets: DefaultLatencyBuckets,
+		dataAgeBuckets: DefaultDataAgeBuckets,
+		endpoints:      map[endpointKey]*EndpointMetrics{},
+	}
+}
+
+// Observe implements Metrics.
+func (m *MemoryMetrics) Observe(o *Observation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := endpointKey{path: o.Path, batch: o.Batch, vehicleMake: o.Make}
+	e, ok := m.endpoints[key]
+	if !ok {
+		e = &EndpointMetrics{
+			Path:    o.Path,
+			Batch:   o.Batch,
+			Make:    o.Make,
+			Errors:  map[string]int{},
+			Latency: newHistogram(m.latencyBuckets),
+			DataAge: newHistogram(m.dataAgeBuckets),
+		}
+		m.endpoints[key] = e
+	}
+
+	e.Requests++
+	if o.Attempt > 0 {
+		e.Retries++
+	}
+	if o.StatusCode == http.StatusTooManyRequests {
+		e.RateLimited++
+	}
+	if errorType := o.ErrorLabel(); errorType != "" {
+		e.Errors[errorType]++
+	}
+	e.Latency.observe(o.Latency)
+	if o.DataAge > 0 {
+		e.DataAge.observe(o.DataAge)
+	}
+}
+
+// Endpoints returns a copy of the metrics of every endpoint, sorted by path, batch and make.
+func (m *MemoryMetrics) Endpoints() []EndpointMetrics {
+	m.mu.Lock()
+	endpoints := make([]EndpointMetrics, 0, len(m.endpoints))
+	for _, e := range m.endpoints {
+		c := *e
+		c.Errors = make(map[string]int, len(e.Errors))
+		for errorType, count := range e.Errors {
+			c.Errors[errorType] = count
+		}
+		c.Latency, c.DataAge = e.Latency.copy(), e.DataAge.copy()
+		endpoints = append(endpoints, c)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		a, b := endpoints[i], endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Batch != b.Batch {
+			return !a.Batch
+		}
+		return a.Make < b.Make
+	})
+	return endpoints
+}
+
+// Endpoint returns a copy of the metrics of the requests to path for vehicles of a make, "" being the vehicles
+// whose make is unknown. It returns nil when there was no such request.
+func (m *MemoryMetrics) Endpoint(path Key, batch bool, vehicleMake string) *EndpointMetrics {
+	for _, e := range m.Endpoints() {
+		if e.Path == path && e.Batch == batch && e.Make == vehicleMake {
+			return &e
+		}
+	}
+	return nil
+}
+
+// ErrorLabel returns the error of the observation as counted by MemoryMetrics: its Smartcar error type, or the
+// text of its status code for errors without a type, or "NETWORK" for requests that could not be sent. It is ""
+// for successful requests.
+func (o *Observation) ErrorLabel() string {
+	switch {
+	case o.ErrorType != "":
+		return o.ErrorType
+	case o.StatusCode == 0:
+		return "NETWORK"
+	case o.StatusCode != http.StatusOK:
+		return http.StatusText(o.StatusCode)
+	}
+	return ""
+}
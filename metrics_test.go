@@ -0,0 +1,127 @@
+package smartcar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newMetricsServer returns a server answering the info endpoint with the make of the vehicle, the odometer with
+// data that is half an hour old, and batch requests with a successful and an asleep path. Other vehicles are asleep.
+func newMetricsServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2.0/vehicles/tesla/":
+			w.Write([]byte(`{"id":"tesla","make":"TESLA","model":"Model 3","year":2020}`))
+		case "/v2.0/vehicles/tesla/odometer", "/v2.0/vehicles/other/odometer":
+			w.Header().Set("Sc-Data-Age", time.Now().Add(-30*time.Minute).Format(time.RFC3339))
+			w.Write([]byte(`{"distance":10}`))
+		case "/v2.0/vehicles/tesla/batch":
+			w.Write([]byte(`{"responses":[
+				{"path":"/odometer","code":200,"body":{"distance":10}},
+				{"path":"/location","code":409,"body":{"type":"VEHICLE_STATE","code":"ASLEEP"}}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"type":"VEHICLE_STATE","code":"ASLEEP"}`))
+		}
+	}))
+}
+
+func TestMetrics(t *testing.T) {
+	// Arrange
+	server := newMetricsServer()
+	defer server.Close()
+	metrics := NewMemoryMetrics()
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithMetrics(metrics))
+	tesla := client.NewVehicle(&VehicleParams{ID: "tesla", AccessToken: "token"})
+	other := client.NewVehicle(&VehicleParams{ID: "other", AccessToken: "token"})
+
+	// Act
+	tesla.GetOdometer(context.Background())
+	tesla.GetInfo(context.Background())
+	tesla.GetOdometer(context.Background())
+	tesla.GetLocation(context.Background())
+	tesla.Batch(context.Background(), OdometerPath, LocationPath)
+	other.GetLocation(context.Background())
+	other.GetLocation(context.Background())
+
+	// Assert
+	assert.Len(t, metrics.Endpoints(), 8)
+
+	// The make of a vehicle is unknown until GetInfo returns it.
+	unknown := metrics.Endpoint(OdometerPath, false, "")
+	assert.Equal(t, 1, unknown.Requests)
+	odometer := metrics.Endpoint(OdometerPath, false, "TESLA")
+	assert.Equal(t, 1, odometer.Requests)
+	assert.Empty(t, odometer.Errors)
+	assert.Equal(t, 1, odometer.Latency.Count)
+	assert.Equal(t, 1, odometer.DataAge.Count)
+	// Half an hour old data is counted in the 1h bucket.
+	assert.Equal(t, 1, odometer.DataAge.Counts[3])
+
+	location := metrics.Endpoint(LocationPath, false, "TESLA")
+	assert.Equal(t, map[string]int{"VEHICLE_STATE": 1}, location.Errors)
+	assert.Equal(t, 0, location.DataAge.Count)
+	assert.Equal(t, map[string]int{"VEHICLE_STATE": 2}, metrics.Endpoint(LocationPath, false, "").Errors)
+
+	assert.Equal(t, 1, metrics.Endpoint(batchPath, false, "TESLA").Requests)
+	assert.Empty(t, metrics.Endpoint(OdometerPath, true, "TESLA").Errors)
+	assert.Equal(t, map[string]int{"VEHICLE_STATE": 1}, metrics.Endpoint(LocationPath, true, "TESLA").Errors)
+	assert.Nil(t, metrics.Endpoint(LocationPath, true, ""))
+}
+
+func TestMetricsRetries(t *testing.T) {
+	// Arrange
+	metrics := NewMemoryMetrics()
+	client := NewClient(
+		WithRateLimit(&RateLimitParams{MaxRetries: 1}),
+		WithMetrics(metrics),
+		WithMiddleware(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				return &Response{
+					StatusCode: http.StatusTooManyRequests,
+					Header:     http.Header{"Retry-After": []string{"1"}},
+					Body:       []byte(`{"type":"RATE_LIMIT","code":"VEHICLE"}`),
+				}, nil
+			})
+		}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	vehicle.GetOdometer(context.Background())
+	endpoint := metrics.Endpoint(OdometerPath, false, "")
+
+	// Assert
+	assert.Equal(t, 2, endpoint.Requests)
+	assert.Equal(t, 1, endpoint.Retries)
+	assert.Equal(t, 2, endpoint.RateLimited)
+	assert.Equal(t, map[string]int{"RATE_LIMIT": 2}, endpoint.Errors)
+}
+
+func TestObservationErrorLabel(t *testing.T) {
+	assert.Equal(t, "", (&Observation{StatusCode: http.StatusOK}).ErrorLabel())
+	assert.Equal(t, "NETWORK", (&Observation{}).ErrorLabel())
+	assert.Equal(t, "Bad Gateway", (&Observation{StatusCode: http.StatusBadGateway}).ErrorLabel())
+	assert.Equal(t, "VEHICLE_STATE", (&Observation{StatusCode: http.StatusConflict, ErrorType: "VEHICLE_STATE"}).ErrorLabel())
+}
+
+func TestHistogram(t *testing.T) {
+	// Arrange
+	h := newHistogram([]time.Duration{time.Second, time.Minute})
+
+	// Act
+	h.observe(time.Second)
+	h.observe(2 * time.Second)
+	h.observe(time.Hour)
+
+	// Assert
+	assert.Equal(t, []int{1, 1, 1}, h.Counts)
+	assert.Equal(t, 3, h.Count)
+	assert.Equal(t, time.Hour+3*time.Second, h.Sum)
+}
@@ -0,0 +1,164 @@
+package smartcar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+)
+
+// Request is a request to Smartcar's API, as seen by a Middleware. Middlewares can change it before calling the
+// next RoundTripper, i.e. to set a header.
+type Request struct {
+	Context context.Context
+	Method  string
+	URL     string
+	// Header holds the headers sent with the request, including Authorization.
+	Header http.Header
+	Body   []byte
+	// VehicleID and Path are set for vehicle requests, i.e. OdometerPath. The path of batch requests is "/batch".
+	VehicleID string
+	Path      Key
+	// Attempt counts the retries of the request by WithRateLimit.
+	Attempt int
+
+	// target is the value the response is decoded into.
+	target interface{}
+}
+
+// Response is the response of a Request, as seen by a Middleware.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Result is the decoded response, i.e. an *Odometer, it is nil when the request failed. Middlewares that answer
+	// requests without calling the next RoundTripper leave it nil: their responses are decoded from Body.
+	Result interface{}
+}
+
+// RoundTripper sends a Request to Smartcar's API. It returns the Response along with the *Error of requests
+// rejected by the API, and a nil Response when the request could not be sent.
+type RoundTripper interface {
+	RoundTrip(*Request) (*Response, error)
+}
+
+// RoundTripperFunc is a function implementing RoundTripper.
+type RoundTripperFunc func(*Request) (*Response, error)
+
+// RoundTrip implements RoundTripper.
+func (f RoundTripperFunc) RoundTrip(r *Request) (*Response, error) {
+	return f(r)
+}
+
+// Middleware wraps the RoundTripper of the client, i.e. to log, measure or audit requests.
+type Middleware func(next RoundTripper) RoundTripper
+
+// WithMiddleware adds middlewares around the requests of the client. The first middleware is the outermost one.
+// Middlewares see every attempt of a request retried by WithRateLimit, but not the responses served by WithCache.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// chainMiddlewares wraps the backend in middlewares, the first one being the outermost.
+func chainMiddlewares(c *backend, middlewares []Middleware) RoundTripper {
+	var rt RoundTripper = RoundTripperFunc(c.roundTrip)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = c.decoding(middlewares[i](rt))
+	}
+	return rt
+}
+
+// decoding decodes the responses of next that have no Result, so that every middleware sees decoded responses and
+// the errors of failed responses.
+func (c *backend) decoding(next RoundTripper) RoundTripper {
+	return RoundTripperFunc(func(r *Request) (*Response, error) {
+		res, err := next.RoundTrip(r)
+		if err != nil || res == nil || res.Result != nil {
+			return res, err
+		}
+		result := newTarget(r.target)
+		if err := c.decode(res, result); err != nil {
+			return res, err
+		}
+		res.Result = result
+		return res, nil
+	})
+}
+
+// roundTrip is the innermost RoundTripper, it sends r and decodes the response.
+func (c *backend) roundTrip(r *Request) (*Response, error) {
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
+	}
+	req, err := http.NewRequest(r.Method, r.URL, body)
+	if err != nil {
+		return nil, errors.New("Error creating New Request")
+	}
+	req = req.WithContext(r.Context)
+	req.Header = r.Header
+
+	res, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	result := newTarget(r.target)
+	if err := c.decode(res, result); err != nil {
+		return res, err
+	}
+	res.Result = result
+	return res, nil
+}
+
+// callMiddlewares sends req through the middlewares of the backend.
+func (c *backend) callMiddlewares(req *http.Request, params backendClientParams) error {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+
+	res, err := c.roundTripper.RoundTrip(&Request{
+		Context:   params.ctx,
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		Header:    req.Header,
+		Body:      body,
+		VehicleID: params.vehicleID,
+		Path:      Key(params.path),
+		Attempt:   params.attempt,
+		target:    params.target,
+	})
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return errors.New("smartcar: middleware returned no response")
+	}
+	return setTarget(params.target, res.Result)
+}
+
+// newTarget returns a new value of the type target points to, so that every response is decoded into a new value
+// and a retried request keeps none of the fields of an earlier response.
+func newTarget(target interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(target).Elem()).Interface()
+}
+
+// setTarget copies the decoded result of the final response of a request into its target.
+func setTarget(target, result interface{}) error {
+	value := reflect.ValueOf(result)
+	if value.Type() != reflect.TypeOf(target) {
+		return fmt.Errorf("smartcar: middleware returned a %T result instead of %T", result, target)
+	}
+	reflect.ValueOf(target).Elem().Set(value.Elem())
+	return nil
+}
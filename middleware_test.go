@@ -0,0 +1,221 @@
+package smartcar
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newMiddlewareServer returns a server answering the odometer endpoint with the distance sent in the X-Distance
+// header, and rejecting the other requests because the vehicle is asleep.
+func newMiddlewareServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Sc-Request-Id", "request-id")
+		if r.URL.Path != "/v2.0/vehicles/vehicle-id/odometer" {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"type":"VEHICLE_STATE","code":"ASLEEP"}`))
+			return
+		}
+		w.Write([]byte(`{"distance":` + r.Header.Get("X-Distance") + `}`))
+	}))
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	// Arrange
+	server := newMiddlewareServer()
+	defer server.Close()
+	var calls []string
+	var seen *Request
+	var result interface{}
+	record := func(name string) Middleware {
+		return func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				calls = append(calls, name)
+				res, err := next.RoundTrip(r)
+				calls = append(calls, name)
+				return res, err
+			})
+		}
+	}
+	inject := func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(r *Request) (*Response, error) {
+			r.Header.Set("X-Distance", "42")
+			seen = r
+			res, err := next.RoundTrip(r)
+			result = res.Result
+			return res, err
+		})
+	}
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithMiddleware(record("outer"), record("inner")),
+		WithMiddleware(inject),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	odometer, err := vehicle.GetOdometer(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 42.0, odometer.Distance.Value)
+	assert.Equal(t, "request-id", odometer.RequestID)
+	assert.Equal(t, []string{"outer", "inner", "inner", "outer"}, calls)
+	assert.Equal(t, http.MethodGet, seen.Method)
+	assert.Equal(t, server.URL+"/v2.0/vehicles/vehicle-id/odometer", seen.URL)
+	assert.Equal(t, "Bearer token", seen.Header.Get("Authorization"))
+	assert.Equal(t, "vehicle-id", seen.VehicleID)
+	assert.Equal(t, OdometerPath, seen.Path)
+	assert.Equal(t, odometer, result)
+}
+
+func TestMiddlewareError(t *testing.T) {
+	// Arrange
+	server := newMiddlewareServer()
+	defer server.Close()
+	var response *Response
+	var body []byte
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithMiddleware(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				body = r.Body
+				res, err := next.RoundTrip(r)
+				response = res
+				return res, err
+			})
+		}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	_, err := vehicle.Lock(context.Background())
+
+	// Assert
+	assert.Equal(t, "ASLEEP", err.(*Error).Code)
+	assert.Equal(t, `{"action":"LOCK"}`, string(body))
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, `{"type":"VEHICLE_STATE","code":"ASLEEP"}`, string(response.Body))
+	assert.Nil(t, response.Result)
+}
+
+func TestMiddlewareLargeErrorBody(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write(bytes.Repeat([]byte("x"), 2*maxErrorBodyBytes))
+	}))
+	defer server.Close()
+	var response *Response
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithMiddleware(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				res, err := next.RoundTrip(r)
+				response = res
+				return res, err
+			})
+		}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	_, err := vehicle.GetOdometer(context.Background())
+
+	// Assert
+	assert.Equal(t, http.StatusBadGateway, err.(*Error).StatusCode)
+	assert.Len(t, response.Body, maxErrorBodyBytes)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	// Arrange
+	client := NewClient(
+		WithMiddleware(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				return &Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Sc-Unit-System": []string{"imperial"}},
+					Body:       []byte(`{"distance":10}`),
+				}, nil
+			})
+		}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	odometer, err := vehicle.GetOdometer(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, odometer.Distance.Value)
+	assert.Equal(t, Imperial, odometer.UnitSystem)
+}
+
+func TestMiddlewareCallsNextTwice(t *testing.T) {
+	// Arrange
+	bodies := []string{`{"latitude":1,"longitude":2,"speed":30}`, `{"latitude":3,"longitude":4}`}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bodies[0]))
+		bodies = bodies[1:]
+	}))
+	defer server.Close()
+	var first interface{}
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithMiddleware(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				res, err := next.RoundTrip(r)
+				if err != nil {
+					return res, err
+				}
+				first = res.Result
+				return next.RoundTrip(r)
+			})
+		}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	location, err := vehicle.GetLocation(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, location.Latitude)
+	assert.Nil(t, location.Speed)
+	assert.Equal(t, 1.0, first.(*Location).Latitude)
+	assert.NotNil(t, first.(*Location).Speed)
+}
+
+func TestMiddlewareRetries(t *testing.T) {
+	// Arrange
+	var attempts []int
+	client := NewClient(
+		WithRateLimit(&RateLimitParams{MaxRetries: 1}),
+		WithMiddleware(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				attempts = append(attempts, r.Attempt)
+				return &Response{
+					StatusCode: http.StatusTooManyRequests,
+					Header:     http.Header{"Retry-After": []string{"1"}},
+					Body:       []byte(`{"type":"RATE_LIMIT","code":"VEHICLE"}`),
+				}, nil
+			})
+		}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	_, err := vehicle.GetOdometer(context.Background())
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, err.(*Error).StatusCode)
+	assert.Equal(t, []int{0, 1}, attempts)
+}
@@ -0,0 +1,99 @@
+package smartcar
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ClientOption configures a Client created with NewClient.
+type ClientOption func(*clientOptions)
+
+// clientOptions holds the configuration of NewClient.
+type clientOptions struct {
+	backend        *backend
+	rateLimit      *RateLimitParams
+	cache          *CacheParams
+	fixtures       *FixtureParams
+	faults         *FaultParams
+	middlewares    []Middleware
+	makes          *vehicleMakes
+	circuitBreaker *CircuitBreaker
+}
+
+// newBackendClient builds the backendClient of a client, wrapping the backend in the optional layers.
+func (o *clientOptions) newBackendClient() backendClient {
+	if o.fixtures != nil {
+		o.backend.httpClient = newFixtureClient(o.backend.httpClient, o.fixtures)
+	}
+	if len(o.middlewares) > 0 {
+		o.backend.roundTripper = chainMiddlewares(o.backend, o.middlewares)
+	}
+	var sC backendClient = o.backend
+	if o.faults != nil {
+		sC = newFaultInjector(sC, o.faults)
+	}
+	// Fleets rate limit their requests here even without WithRateLimit.
+	sC = newRateLimiter(sC, o.rateLimit)
+	// Open circuits fail before waiting for the rate limits.
+	if o.circuitBreaker != nil {
+		sC = newBreakerBackend(sC, o.circuitBreaker, o.vehicleMakes())
+	}
+	// Cached responses don't count towards the rate limits.
+	if o.cache != nil {
+		sC = newCache(sC, o.cache)
+	}
+	return sC
+}
+
+// WithBaseURL sends every request of the client to baseURL instead of Smartcar's API and authentication servers,
+// i.e. to a local fake server in tests. The path of Smartcar's endpoints is appended to the path of baseURL.
+// Invalid URLs are ignored.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(o *clientOptions) {
+		u, err := url.Parse(baseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		o.backend.baseURL = u
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests. It defaults to a client with a 310 seconds timeout.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.backend.httpClient = httpClient
+	}
+}
+
+// WithRateLimit throttles the requests of the client before they are sent, and when Smartcar's API rejects them
+// with a 429 Too Many Requests.
+func WithRateLimit(params *RateLimitParams) ClientOption {
+	return func(o *clientOptions) {
+		o.rateLimit = params
+	}
+}
+
+// WithCache caches the responses of the vehicle GET requests and of vehicle.Batch. Concurrent identical requests
+// are sent once.
+func WithCache(params *CacheParams) ClientOption {
+	return func(o *clientOptions) {
+		o.cache = params
+	}
+}
+
+// rewriteURL sends rawURL to the base URL of the backend, if it has one.
+func (c *backend) rewriteURL(rawURL string) string {
+	if c.baseURL == nil {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = c.baseURL.Scheme
+	u.Host = c.baseURL.Host
+	u.Path = c.baseURL.Path + u.Path
+	return u.String()
+}
@@ -0,0 +1,34 @@
+package smartcar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBaseURL(t *testing.T) {
+	// Arrange
+	b := &backend{}
+	WithBaseURL("http://127.0.0.1:8080/smartcar/")(&clientOptions{backend: b})
+
+	// Act
+	api := b.rewriteURL("https://api.smartcar.com/v2.0/vehicles/id/odometer?units=metric")
+	token := b.rewriteURL(exchangeURL)
+
+	// Assert
+	assert.Equal(t, "http://127.0.0.1:8080/smartcar/v2.0/vehicles/id/odometer?units=metric", api)
+	assert.Equal(t, "http://127.0.0.1:8080/smartcar/oauth/token/", token)
+}
+
+func TestWithBaseURLInvalid(t *testing.T) {
+	// Arrange
+	b := &backend{}
+	WithBaseURL("not a url")(&clientOptions{backend: b})
+
+	// Act
+	rewritten := b.rewriteURL(exchangeURL)
+
+	// Assert
+	assert.Nil(t, b.baseURL)
+	assert.Equal(t, exchangeURL, rewritten)
+}
@@ -0,0 +1,83 @@
+// Package otelsmartcar traces the requests of a smartcar.Client with OpenTelemetry. It is a separate module, so
+// that the SDK does not depend on OpenTelemetry.
+//
+//	smartcarClient := smartcar.NewClient(smartcar.WithTracer(otelsmartcar.NewTracer(nil)))
+package otelsmartcar
+
+import (
+	"context"
+	"fmt"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer created from the global TracerProvider.
+const instrumentationName = "github.com/smartcar/go-sdk/otelsmartcar"
+
+// NewTracer returns a smartcar.Tracer starting client spans with tracer. It uses a tracer of the global
+// TracerProvider when tracer is nil.
+func NewTracer(tracer trace.Tracer) smartcar.Tracer {
+	if tracer == nil {
+		tracer = otel.Tracer(instrumentationName)
+	}
+	return &otelTracer{tracer: tracer}
+}
+
+// otelTracer implements smartcar.Tracer with an OpenTelemetry tracer.
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+// Start implements smartcar.Tracer.
+func (t *otelTracer) Start(ctx context.Context, name string, attributes ...smartcar.Attribute) (context.Context, smartcar.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attributes)...),
+	)
+	return ctx, &otelSpan{span: span}
+}
+
+// otelSpan implements smartcar.Span with an OpenTelemetry span.
+type otelSpan struct {
+	span trace.Span
+}
+
+// SetAttributes implements smartcar.Span.
+func (s *otelSpan) SetAttributes(attributes ...smartcar.Attribute) {
+	s.span.SetAttributes(convert(attributes)...)
+}
+
+// End implements smartcar.Span.
+func (s *otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// convert converts attributes to OpenTelemetry attributes.
+func convert(attributes []smartcar.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attributes))
+	for _, a := range attributes {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
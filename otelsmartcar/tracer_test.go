@@ -0,0 +1,54 @@
+package otelsmartcar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Sc-Request-Id", "request-id")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"type":"VEHICLE_STATE","code":"ASLEEP"}`))
+	}))
+	defer server.Close()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := smartcar.NewClient(
+		smartcar.WithBaseURL(server.URL),
+		smartcar.WithHTTPClient(server.Client()),
+		smartcar.WithTracer(NewTracer(provider.Tracer("test"))),
+	)
+	vehicle := client.NewVehicle(&smartcar.VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	// Act
+	_, err := vehicle.GetOdometer(ctx)
+	parent.End()
+
+	// Assert
+	assert.NotNil(t, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "smartcar GET /odometer", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), attribute.String(smartcar.AttributeVehicleID, "vehicle-id"))
+	assert.Contains(t, span.Attributes(), attribute.String(smartcar.AttributeRequestID, "request-id"))
+	assert.Contains(t, span.Attributes(), attribute.Int(smartcar.AttributeHTTPStatusCode, http.StatusConflict))
+	assert.Contains(t, span.Attributes(), attribute.String(smartcar.AttributeErrorType, "VEHICLE_STATE"))
+	assert.Len(t, span.Events(), 1)
+}
@@ -0,0 +1,143 @@
+// Package promsmartcar exports the metrics of the requests of a smartcar.Client to Prometheus. It is a separate
+// module, so that the SDK does not depend on Prometheus.
+//
+//	collector := promsmartcar.NewCollector(&promsmartcar.CollectorParams{})
+//	prometheus.MustRegister(collector)
+//	smartcarClient := smartcar.NewClient(smartcar.WithMetrics(collector))
+package promsmartcar
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// Labels of the metrics. path is the endpoint, i.e. "/odometer", batch whether the request was a path of a batch
+// request, and make the make of the vehicle once vehicle.GetInfo returned it.
+var (
+	endpointLabels = []string{"path", "batch", "make"}
+	requestLabels  = []string{"path", "batch", "make", "code"}
+	errorLabels    = []string{"path", "batch", "make", "error_type", "error_code"}
+)
+
+// CollectorParams is a param in promsmartcar.NewCollector
+type CollectorParams struct {
+	// Namespace prefixes the names of the metrics, it defaults to "smartcar".
+	Namespace string
+	// LatencyBuckets and DataAgeBuckets are the buckets of the histograms, in seconds. They default to the buckets
+	// of smartcar.MemoryMetrics.
+	LatencyBuckets []float64
+	DataAgeBuckets []float64
+}
+
+// Collector is a smartcar.Metrics and a prometheus.Collector.
+type Collector struct {
+	requests    *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	retries     *prometheus.CounterVec
+	rateLimited *prometheus.CounterVec
+	latency     *prometheus.HistogramVec
+	dataAge     *prometheus.HistogramVec
+}
+
+// NewCollector returns a Collector, which must be registered in a prometheus.Registerer.
+func NewCollector(params *CollectorParams) *Collector {
+	namespace := params.Namespace
+	if namespace == "" {
+		namespace = "smartcar"
+	}
+	latencyBuckets := params.LatencyBuckets
+	if latencyBuckets == nil {
+		latencyBuckets = seconds(smartcar.DefaultLatencyBuckets)
+	}
+	dataAgeBuckets := params.DataAgeBuckets
+	if dataAgeBuckets == nil {
+		dataAgeBuckets = seconds(smartcar.DefaultDataAgeBuckets)
+	}
+
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests to Smartcar's API, including retries, by status code.",
+		}, requestLabels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Failed requests to Smartcar's API, by Smartcar error type and code.",
+		}, errorLabels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Retries of requests to Smartcar's API.",
+		}, endpointLabels),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_total",
+			Help:      "Requests to Smartcar's API rejected with a 429 Too Many Requests.",
+		}, endpointLabels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the requests to Smartcar's API.",
+			Buckets:   latencyBuckets,
+		}, endpointLabels),
+		dataAge: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "data_age_seconds",
+			Help:      "How long ago the data of the responses of Smartcar's API was fetched from the vehicles.",
+			Buckets:   dataAgeBuckets,
+		}, endpointLabels),
+	}
+}
+
+// Observe implements smartcar.Metrics.
+func (c *Collector) Observe(o *smartcar.Observation) {
+	path, batch := string(o.Path), strconv.FormatBool(o.Batch)
+
+	c.requests.WithLabelValues(path, batch, o.Make, strconv.Itoa(o.StatusCode)).Inc()
+	if errorLabel := o.ErrorLabel(); errorLabel != "" {
+		c.errors.WithLabelValues(path, batch, o.Make, errorLabel, o.ErrorCode).Inc()
+	}
+	if o.Attempt > 0 {
+		c.retries.WithLabelValues(path, batch, o.Make).Inc()
+	}
+	if o.StatusCode == http.StatusTooManyRequests {
+		c.rateLimited.WithLabelValues(path, batch, o.Make).Inc()
+	}
+	c.latency.WithLabelValues(path, batch, o.Make).Observe(o.Latency.Seconds())
+	if o.DataAge > 0 {
+		c.dataAge.WithLabelValues(path, batch, o.Make).Observe(o.DataAge.Seconds())
+	}
+}
+
+// seconds converts buckets to seconds.
+func seconds(buckets []time.Duration) []float64 {
+	s := make([]float64, len(buckets))
+	for i, b := range buckets {
+		s[i] = b.Seconds()
+	}
+	return s
+}
+
+// collectors returns the metrics of the collector.
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.requests, c.errors, c.retries, c.rateLimited, c.latency, c.dataAge}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
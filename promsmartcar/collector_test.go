@@ -0,0 +1,66 @@
+package promsmartcar
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	// Arrange
+	collector := NewCollector(&CollectorParams{})
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	// Act
+	collector.Observe(&smartcar.Observation{
+		Path:       smartcar.OdometerPath,
+		Make:       "TESLA",
+		StatusCode: http.StatusOK,
+		Latency:    200 * time.Millisecond,
+		DataAge:    time.Minute,
+	})
+	collector.Observe(&smartcar.Observation{
+		Path:       smartcar.LocationPath,
+		Batch:      true,
+		Make:       "TESLA",
+		StatusCode: http.StatusConflict,
+		ErrorType:  "VEHICLE_STATE",
+		ErrorCode:  "ASLEEP",
+	})
+	collector.Observe(&smartcar.Observation{
+		Path:       smartcar.OdometerPath,
+		StatusCode: http.StatusTooManyRequests,
+		ErrorType:  "RATE_LIMIT",
+		ErrorCode:  "VEHICLE",
+		Attempt:    1,
+	})
+
+	// Assert
+	err := testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP smartcar_errors_total Failed requests to Smartcar's API, by Smartcar error type and code.
+# TYPE smartcar_errors_total counter
+smartcar_errors_total{batch="false",error_code="VEHICLE",error_type="RATE_LIMIT",make="",path="/odometer"} 1
+smartcar_errors_total{batch="true",error_code="ASLEEP",error_type="VEHICLE_STATE",make="TESLA",path="/location"} 1
+# HELP smartcar_rate_limited_total Requests to Smartcar's API rejected with a 429 Too Many Requests.
+# TYPE smartcar_rate_limited_total counter
+smartcar_rate_limited_total{batch="false",make="",path="/odometer"} 1
+# HELP smartcar_requests_total Requests to Smartcar's API, including retries, by status code.
+# TYPE smartcar_requests_total counter
+smartcar_requests_total{batch="false",code="200",make="TESLA",path="/odometer"} 1
+smartcar_requests_total{batch="false",code="429",make="",path="/odometer"} 1
+smartcar_requests_total{batch="true",code="409",make="TESLA",path="/location"} 1
+# HELP smartcar_retries_total Retries of requests to Smartcar's API.
+# TYPE smartcar_retries_total counter
+smartcar_retries_total{batch="false",make="",path="/odometer"} 1
+`), "smartcar_errors_total", "smartcar_rate_limited_total", "smartcar_requests_total", "smartcar_retries_total")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, testutil.CollectAndCount(collector, "smartcar_request_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "smartcar_data_age_seconds"))
+}
@@ -0,0 +1,286 @@
+package smartcar
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultRetryAfter is how long requests are paused after a 429 response without a Retry-After header.
+const defaultRetryAfter = time.Second
+
+// vehicleLimitSweepInterval is how often the limits of idle vehicles are dropped.
+const vehicleLimitSweepInterval = time.Minute
+
+// RateLimitParams is a param in smartcar.WithRateLimit
+type RateLimitParams struct {
+	// ApplicationRate is the number of requests per second sent by the client, and ApplicationBurst how many
+	// of them can be sent at once. There is no limit when ApplicationRate is 0.
+	ApplicationRate  float64
+	ApplicationBurst int
+	// VehicleRate is the number of requests per second sent to each vehicle, and VehicleBurst how many of them
+	// can be sent at once. There is no limit when VehicleRate is 0.
+	VehicleRate  float64
+	VehicleBurst int
+	// MaxRetries is how many times a request rejected with a 429 is sent again, once its Retry-After elapsed.
+	MaxRetries int
+}
+
+// tokenBucket is a token bucket rate limiter. A nil *tokenBucket does not limit.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a bucket that allows rate requests per second on average, and up to burst at once.
+// It returns nil when rate is not positive.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until a request is allowed or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	// Reserve a token, waiting for the bucket to refill when it is empty.
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if err := sleep(ctx, delay); err != nil {
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// full reports whether the bucket refilled at now. A nil bucket is always full.
+func (b *tokenBucket) full(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// sleep waits for d, it returns early with the error of ctx once ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limit throttles the requests of a vehicle or of the application.
+type limit struct {
+	bucket *tokenBucket
+	// users counts the requests using the limit of a vehicle, it is guarded by the mutex of the rateLimiter.
+	users int
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+// wait blocks until a request is allowed or ctx is done.
+func (l *limit) wait(ctx context.Context) error {
+	l.mu.Lock()
+	pause := time.Until(l.until)
+	l.mu.Unlock()
+
+	if err := sleep(ctx, pause); err != nil {
+		return err
+	}
+	return l.bucket.wait(ctx)
+}
+
+// idle reports whether the limit is in the same state as a new one: it is not paused and its bucket is full.
+func (l *limit) idle(now time.Time) bool {
+	l.mu.Lock()
+	paused := now.Before(l.until)
+	l.mu.Unlock()
+	return !paused && l.bucket.full(now)
+}
+
+// pause blocks requests for d.
+func (l *limit) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.until) {
+		l.until = until
+	}
+}
+
+// rateLimiter is a backendClient that throttles the requests of an application and of its vehicles. A 429 response
+// pauses the requests of the vehicle, or of the whole application when Smartcar's API limit was reached. The limits
+// of idle vehicles are dropped, their next requests get a new one in the same state.
+//
+// The requests of a fleet are throttled by the rateLimiter of the fleet instead, which is not part of the chain of
+// the client. A client without WithRateLimit has a rateLimiter that only throttles the requests of fleets.
+type rateLimiter struct {
+	next        backendClient
+	params      RateLimitParams
+	fleetsOnly  bool
+	application *limit
+
+	mu        sync.Mutex
+	vehicles  map[string]*limit
+	lastSweep time.Time
+}
+
+// newRateLimiter wraps next in a rateLimiter. With nil params, it only throttles the requests of fleets.
+func newRateLimiter(next backendClient, params *RateLimitParams) backendClient {
+	r := &rateLimiter{
+		next:       next,
+		fleetsOnly: params == nil,
+		vehicles:   make(map[string]*limit),
+		lastSweep:  time.Now(),
+	}
+	if params != nil {
+		r.params = *params
+	}
+	r.application = &limit{bucket: newTokenBucket(r.params.ApplicationRate, r.params.ApplicationBurst)}
+	return r
+}
+
+// acquire returns the limit of a vehicle, or nil for requests that are not sent to a vehicle. The limit is not
+// dropped until it is released.
+func (r *rateLimiter) acquire(vehicleID string) *limit {
+	if vehicleID == "" {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := time.Now(); now.Sub(r.lastSweep) >= vehicleLimitSweepInterval {
+		r.sweep(now)
+	}
+	l, ok := r.vehicles[vehicleID]
+	if !ok {
+		l = &limit{bucket: newTokenBucket(r.params.VehicleRate, r.params.VehicleBurst)}
+		r.vehicles[vehicleID] = l
+	}
+	l.users++
+	return l
+}
+
+// release releases a limit returned by acquire.
+func (r *rateLimiter) release(l *limit) {
+	if l == nil {
+		return
+	}
+	r.mu.Lock()
+	l.users--
+	r.mu.Unlock()
+}
+
+// sweep drops the limits of the idle vehicles without requests in progress. It must be called with r.mu held.
+func (r *rateLimiter) sweep(now time.Time) {
+	r.lastSweep = now
+	for vehicleID, l := range r.vehicles {
+		if l.users == 0 && l.idle(now) {
+			delete(r.vehicles, vehicleID)
+		}
+	}
+}
+
+// Call implements backendClient.
+func (r *rateLimiter) Call(params backendClientParams) error {
+	switch {
+	case params.rateLimiter != nil:
+		return params.rateLimiter.call(r.next, params, r.params.MaxRetries)
+	case r.fleetsOnly:
+		return r.next.Call(params)
+	}
+	return r.call(r.next, params, r.params.MaxRetries)
+}
+
+// call sends the request to next within the limits of r, and retries it up to maxRetries times after a 429 response.
+func (r *rateLimiter) call(next backendClient, params backendClientParams, maxRetries int) error {
+	vehicle := r.acquire(params.vehicleID)
+	defer r.release(vehicle)
+	body, err := newReplayableBody(params.body)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if vehicle != nil {
+			if err := vehicle.wait(params.ctx); err != nil {
+				return err
+			}
+		}
+		if err := r.application.wait(params.ctx); err != nil {
+			return err
+		}
+
+		params.body = body()
+		params.attempt = attempt
+		err := next.Call(params)
+		scErr, ok := err.(*Error)
+		if !ok || scErr.StatusCode != http.StatusTooManyRequests {
+			return err
+		}
+
+		retryAfter := scErr.RetryAfter
+		if retryAfter <= 0 {
+			retryAfter = defaultRetryAfter
+		}
+		// Smartcar's API reports which limit was reached in the code of the error.
+		if vehicle != nil && scErr.Code != "SMARTCAR_API" {
+			vehicle.pause(retryAfter)
+		} else {
+			r.application.pause(retryAfter)
+		}
+		if attempt >= maxRetries {
+			return err
+		}
+	}
+}
+
+// newReplayableBody reads body so it can be sent again, and returns a function returning a new reader of it.
+// Readers keep their type, which sets the Content-Type of the request.
+func newReplayableBody(body io.Reader) (func() io.Reader, error) {
+	if body == nil {
+		return func() io.Reader { return nil }, nil
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := body.(*bytes.Buffer); ok {
+		return func() io.Reader { return bytes.NewBuffer(b) }, nil
+	}
+	return func() io.Reader { return bytes.NewReader(b) }, nil
+}
@@ -0,0 +1,206 @@
+package smartcar
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	// Arrange
+	bucket := newTokenBucket(100, 2)
+	start := time.Now()
+
+	// Act
+	for i := 0; i < 4; i++ {
+		bucket.wait(context.Background())
+	}
+
+	// Assert
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 15*time.Millisecond, elapsed.String())
+}
+
+func TestTokenBucketCanceled(t *testing.T) {
+	// Arrange
+	bucket := newTokenBucket(1, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	bucket.wait(ctx)
+
+	// Act
+	err := bucket.wait(ctx)
+
+	// Assert
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.InDelta(t, 0, bucket.tokens, 0.1)
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	// Arrange
+	bucket := newTokenBucket(0, 0)
+
+	// Act
+	err := bucket.wait(context.Background())
+
+	// Assert
+	assert.Nil(t, bucket)
+	assert.Nil(t, err)
+}
+
+// scriptedBackend returns the scripted errors in order, then nil, and records the calls it received.
+type scriptedBackend struct {
+	mu     sync.Mutex
+	errors []error
+	calls  []backendClientParams
+	bodies []string
+	times  []time.Time
+}
+
+func (b *scriptedBackend) Call(params backendClientParams) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	body := ""
+	if params.body != nil {
+		read, _ := ioutil.ReadAll(params.body)
+		body = string(read)
+	}
+	b.calls = append(b.calls, params)
+	b.bodies = append(b.bodies, body)
+	b.times = append(b.times, time.Now())
+	if len(b.errors) == 0 {
+		return nil
+	}
+	err := b.errors[0]
+	b.errors = b.errors[1:]
+	return err
+}
+
+func TestRateLimiterVehicleRate(t *testing.T) {
+	// Arrange
+	next := &scriptedBackend{}
+	limiter := newRateLimiter(next, &RateLimitParams{VehicleRate: 50})
+	start := time.Now()
+
+	// Act
+	for i := 0; i < 3; i++ {
+		limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1"})
+	}
+	vehicleElapsed := time.Since(start)
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v2"})
+	limiter.Call(backendClientParams{ctx: context.Background()})
+
+	// Assert
+	assert.True(t, vehicleElapsed >= 35*time.Millisecond, vehicleElapsed.String())
+	assert.True(t, time.Since(start)-vehicleElapsed < 15*time.Millisecond)
+	assert.Len(t, next.calls, 5)
+}
+
+func TestRateLimiterRetry(t *testing.T) {
+	// Arrange
+	next := &scriptedBackend{errors: []error{
+		&Error{StatusCode: http.StatusTooManyRequests, Code: "VEHICLE", RetryAfter: 30 * time.Millisecond},
+	}}
+	limiter := newRateLimiter(next, &RateLimitParams{MaxRetries: 2})
+	start := time.Now()
+
+	// Act
+	err := limiter.Call(backendClientParams{
+		ctx:       context.Background(),
+		vehicleID: "v1",
+		body:      bytes.NewBufferString(`{"action":"LOCK"}`),
+	})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+	assert.Len(t, next.calls, 2)
+	assert.Equal(t, 1, next.calls[1].attempt)
+	assert.Equal(t, []string{`{"action":"LOCK"}`, `{"action":"LOCK"}`}, next.bodies)
+	assert.IsType(t, &bytes.Buffer{}, next.calls[1].body)
+}
+
+func TestRateLimiterPausesVehicle(t *testing.T) {
+	// Arrange
+	rejected := &Error{StatusCode: http.StatusTooManyRequests, Code: "VEHICLE", RetryAfter: 30 * time.Millisecond}
+	next := &scriptedBackend{errors: []error{rejected}}
+	limiter := newRateLimiter(next, &RateLimitParams{})
+
+	// Act
+	err := limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1"})
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v2"})
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1"})
+
+	// Assert
+	assert.Equal(t, rejected, err)
+	assert.True(t, next.times[1].Sub(next.times[0]) < 15*time.Millisecond)
+	assert.True(t, next.times[2].Sub(next.times[0]) >= 30*time.Millisecond)
+}
+
+func TestRateLimiterPausesApplication(t *testing.T) {
+	// Arrange
+	next := &scriptedBackend{errors: []error{
+		&Error{StatusCode: http.StatusTooManyRequests, Code: "SMARTCAR_API", RetryAfter: 30 * time.Millisecond},
+	}}
+	limiter := newRateLimiter(next, &RateLimitParams{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1"})
+	err := limiter.Call(backendClientParams{ctx: ctx, vehicleID: "v2"})
+
+	// Assert
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, next.calls, 1)
+}
+
+func TestRateLimiterFleetsOnly(t *testing.T) {
+	// Arrange
+	rejected := &Error{StatusCode: http.StatusTooManyRequests, Code: "VEHICLE", RetryAfter: 30 * time.Millisecond}
+	next := &scriptedBackend{errors: []error{rejected, nil, rejected}}
+	limiter := newRateLimiter(next, nil)
+	fleetLimiter := newRateLimiter(nil, &RateLimitParams{}).(*rateLimiter)
+
+	// Act
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1"})
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1"})
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1", rateLimiter: fleetLimiter})
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1", rateLimiter: fleetLimiter})
+
+	// Assert
+	// The requests of the client are not paused, those of the fleet are paused by the limiter of the fleet.
+	assert.True(t, next.times[1].Sub(next.times[0]) < 15*time.Millisecond)
+	assert.True(t, next.times[3].Sub(next.times[2]) >= 30*time.Millisecond)
+	assert.Len(t, limiter.(*rateLimiter).vehicles, 0)
+	assert.Len(t, fleetLimiter.vehicles, 1)
+}
+
+func TestRateLimiterDropsIdleVehicles(t *testing.T) {
+	// Arrange
+	next := &scriptedBackend{errors: []error{
+		nil,
+		&Error{StatusCode: http.StatusTooManyRequests, Code: "VEHICLE", RetryAfter: time.Minute},
+	}}
+	limiter := newRateLimiter(next, &RateLimitParams{VehicleRate: 1000}).(*rateLimiter)
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v1"})
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v2"})
+	time.Sleep(5 * time.Millisecond)
+	limiter.lastSweep = time.Now().Add(-vehicleLimitSweepInterval)
+
+	// Act
+	limiter.Call(backendClientParams{ctx: context.Background(), vehicleID: "v3"})
+
+	// Assert
+	// v1 refilled its bucket, v2 is still paused by its 429.
+	assert.Len(t, limiter.vehicles, 2)
+	assert.Contains(t, limiter.vehicles, "v2")
+	assert.Contains(t, limiter.vehicles, "v3")
+	assert.Equal(t, 0, limiter.vehicles["v3"].users)
+}
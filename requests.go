@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"runtime"
 	"time"
@@ -20,6 +21,18 @@ const (
 // requestParams is a helper struct to send accross the requests methods.
 type requestParams struct {
 	UnitSystem UnitSystem
+	MaxAge     time.Duration
+}
+
+// RequestOption overrides the parameters of a single vehicle request, without changing the vehicle.
+type RequestOption func(*requestParams)
+
+// WithUnits sets the unit system used for a single request.
+// (i.e. vehicle.GetOdometer(ctx, smartcar.WithUnits(smartcar.Imperial)))
+func WithUnits(units UnitSystem) RequestOption {
+	return func(p *requestParams) {
+		p.UnitSystem = units
+	}
 }
 
 type backendClientParams struct {
@@ -28,17 +41,49 @@ type backendClientParams struct {
 	requestParams              requestParams
 	body                       io.Reader
 	target                     interface{}
+	// vehicleID and path are set for vehicle requests, attempt counts the retries of the request.
+	vehicleID string
+	path      string
+	attempt   int
+	// rateLimiter limits the requests of a fleet in place of the rate limiter of the client.
+	rateLimiter *rateLimiter
 }
 
 // ResponseHeaders is a struct that has Smartcar's API response headers.
+// DataAge is the time at which the data was fetched from the vehicle, it is the zero time when the
+// response did not include a valid Sc-Data-Age header.
 type ResponseHeaders struct {
 	// Deprecated: Should use DataAge instead of Age
 	Age        string     `json:"age,omitempty"`
-	DataAge    string     `json:"dataAge,omitempty"`
+	DataAge    time.Time  `json:"dataAge,omitzero"`
 	RequestID  string     `json:"requestId,omitempty"`
 	UnitSystem UnitSystem `json:"unitSystem,omitempty"`
 }
 
+// Staleness returns how long ago the data of the response was fetched from the vehicle,
+// or 0 when the response did not include a data age.
+func (h ResponseHeaders) Staleness() time.Duration {
+	if h.DataAge.IsZero() {
+		return 0
+	}
+	return time.Since(h.DataAge)
+}
+
+// parseDataAge parses the ISO 8601 timestamp of a Sc-Data-Age header. Invalid timestamps result in the zero time,
+// the raw header is still available in the deprecated ResponseHeaders.Age.
+func parseDataAge(dataAge string) time.Time {
+	t, err := time.Parse(time.RFC3339, dataAge)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// responseHeaders gives access to the ResponseHeaders embedded in every response.
+func (h *ResponseHeaders) responseHeaders() *ResponseHeaders {
+	return h
+}
+
 // Call creates a http request and calls the Exectue method with it.
 func (c *backend) Call(params backendClientParams) error {
 	req, err := c.newRequest(params)
@@ -46,31 +91,78 @@ func (c *backend) Call(params backendClientParams) error {
 		return err
 	}
 
+	if c.roundTripper != nil {
+		return c.callMiddlewares(req, params)
+	}
 	return c.execute(req, params.target)
 }
 
 // execute executes a req and formats response.
 func (c *backend) execute(req *http.Request, target interface{}) error {
-	client := &http.Client{
-		Timeout: defaultHTTPTimeout,
+	res, err := c.do(req)
+	if err != nil {
+		return err
 	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return newError(res)
+	}
+	return c.decodeResult(res.Header, res.Body, target)
+}
+
+// do sends req with the http client of the backend.
+func (c *backend) do(req *http.Request) (*http.Response, error) {
+	client := c.httpClient
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+	return client.Do(req)
+}
 
-	res, err := client.Do(req)
+// send sends req and reads the response, so that middlewares can see its body. Error bodies are read up to
+// maxErrorBodyBytes.
+func (c *backend) send(req *http.Request) (*Response, error) {
+	res, err := c.do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer res.Body.Close()
 
+	var r io.Reader = res.Body
+	if res.StatusCode != 200 {
+		r = io.LimitReader(res.Body, maxErrorBodyBytes)
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: res.StatusCode, Header: res.Header, Body: body}, nil
+}
+
+// decode formats a response into target, or returns the error of a failed response.
+func (c *backend) decode(res *Response, target interface{}) error {
 	if res.StatusCode != 200 {
-		return errors.New(http.StatusText(res.StatusCode))
+		return newError(&http.Response{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       ioutil.NopCloser(bytes.NewReader(res.Body)),
+		})
 	}
+	return c.decodeResult(res.Header, bytes.NewReader(res.Body), target)
+}
 
-	if err := c.formatHeadersResponse(res.Header, target); err != nil {
+// decodeResult formats the headers and body of a successful response into target.
+func (c *backend) decodeResult(headers http.Header, body io.Reader, target interface{}) error {
+	if err := c.formatHeadersResponse(headers, target); err != nil {
 		return err
 	}
-	if err := c.formatBodyResponse(res.Body, target); err != nil {
+	if err := c.formatBodyResponse(body, target); err != nil {
 		return err
 	}
+	applyUnitSystem(target)
 	return nil
 }
 
@@ -84,7 +176,7 @@ func (c *backend) formatHeadersResponse(headers http.Header, target interface{})
 	unitSystem := unitSystems[headers.Get("Sc-Unit-System")]
 	h := &ResponseHeaders{
 		Age:        headers.Get("Sc-Data-Age"),
-		DataAge:    headers.Get("Sc-Data-Age"),
+		DataAge:    parseDataAge(headers.Get("Sc-Data-Age")),
 		RequestID:  headers.Get("Sc-Request-Id"),
 		UnitSystem: unitSystem,
 	}
@@ -100,7 +192,7 @@ func (c *backend) formatHeadersResponse(headers http.Header, target interface{})
 func (c *backend) newRequest(params backendClientParams) (*http.Request, error) {
 	// Not supported in previous versions og go 1.13
 	// req, err := http.NewRequestWithContext(params.ctx, params.method, params.url, params.body)
-	req, err := http.NewRequest(params.method, params.url, params.body)
+	req, err := http.NewRequest(params.method, c.rewriteURL(params.url), params.body)
 	req = req.WithContext(params.ctx)
 
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -34,7 +35,8 @@ type mockBody struct {
 func (s *RequestTestSuite) TestCall() {
 	defer gock.Off()
 
-	mockAge := "data-age"
+	mockAge := "2018-06-20T01:33:37.078Z"
+	mockDataAge := time.Date(2018, 6, 20, 1, 33, 37, 78000000, time.UTC)
 	mockRequestID := "request-id"
 	mockUnitSystem := Metric
 	mockValue := "mock value"
@@ -42,7 +44,7 @@ func (s *RequestTestSuite) TestCall() {
 		SomeKey: mockValue,
 		ResponseHeaders: ResponseHeaders{
 			Age:        mockAge,
-			DataAge:    mockAge,
+			DataAge:    mockDataAge,
 			RequestID:  mockRequestID,
 			UnitSystem: mockUnitSystem,
 		},
@@ -68,8 +70,28 @@ func (s *RequestTestSuite) TestCall() {
 	assert.Equal(s.T(), expectedResponse, target)
 }
 
+func (s *RequestTestSuite) TestCallError() {
+	defer gock.Off()
+
+	mockURL := "https://example.com"
+	gock.New(mockURL).
+		Get("/").
+		Reply(500)
+
+	err := s.backend.Call(backendClientParams{
+		ctx:    context.TODO(),
+		url:    mockURL,
+		method: http.MethodGet,
+		target: new(mockResponse),
+	})
+
+	assert.Equal(s.T(), &Error{StatusCode: 500}, err)
+	assert.EqualError(s.T(), err, "Internal Server Error")
+}
+
 func (s *RequestTestSuite) TestformatHeadersResponse() {
-	mockAge := "data-age"
+	mockAge := "2018-06-20T01:33:37.078Z"
+	mockDataAge := time.Date(2018, 6, 20, 1, 33, 37, 78000000, time.UTC)
 	mockRequestID := "request-id"
 	mockUnitSystem := Metric
 	mockValue := "mock value"
@@ -77,7 +99,7 @@ func (s *RequestTestSuite) TestformatHeadersResponse() {
 		SomeKey: mockValue,
 		ResponseHeaders: ResponseHeaders{
 			Age:        mockAge,
-			DataAge:    mockAge,
+			DataAge:    mockDataAge,
 			RequestID:  mockRequestID,
 			UnitSystem: mockUnitSystem,
 		},
@@ -98,6 +120,49 @@ func (s *RequestTestSuite) TestformatHeadersResponse() {
 	assert.Equal(s.T(), expectedResponse, target)
 }
 
+func (s *RequestTestSuite) TestformatHeadersResponseInvalidDataAge() {
+	target := new(mockResponse)
+	headers := http.Header{}
+	headers.Add("Sc-Data-Age", "yesterday")
+
+	backend := &backend{}
+	err := backend.formatHeadersResponse(headers, target)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "yesterday", target.Age)
+	assert.True(s.T(), target.DataAge.IsZero())
+	assert.Equal(s.T(), time.Duration(0), target.Staleness())
+}
+
+func (s *RequestTestSuite) TestStaleness() {
+	headers := ResponseHeaders{DataAge: time.Now().Add(-time.Hour)}
+
+	staleness := headers.Staleness()
+
+	assert.True(s.T(), staleness >= time.Hour)
+	assert.True(s.T(), staleness < time.Hour+time.Minute)
+}
+
+func (s *RequestTestSuite) TestResponseHeadersJSON() {
+	withAge := &Odometer{ResponseHeaders: ResponseHeaders{
+		DataAge:   time.Date(2018, 6, 20, 1, 33, 37, 78000000, time.UTC),
+		RequestID: "request-id",
+	}}
+	withoutAge := &Odometer{}
+
+	withAgeJSON, err := json.Marshal(withAge)
+	assert.Nil(s.T(), err)
+	withoutAgeJSON, err := json.Marshal(withoutAge)
+	assert.Nil(s.T(), err)
+	decoded := new(Odometer)
+	err = json.Unmarshal([]byte(`{"distance":1,"dataAge":"2018-06-20T01:33:37.078Z","requestId":"request-id"}`), decoded)
+
+	assert.Nil(s.T(), err)
+	assert.Contains(s.T(), string(withAgeJSON), `"dataAge":"2018-06-20T01:33:37.078Z"`)
+	assert.NotContains(s.T(), string(withoutAgeJSON), "dataAge")
+	assert.Equal(s.T(), withAge.DataAge, decoded.DataAge)
+}
+
 func (s *RequestTestSuite) TestformatBodyResponse() {
 	mockValue := "mock value"
 	expectedResponse := &mockBody{
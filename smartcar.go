@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -67,6 +68,19 @@ type PermissionsParams struct {
 	Permissions []string
 }
 
+// HashChallengeParams is a param in client.HashChallenge
+type HashChallengeParams struct {
+	ManagementToken string
+	Challenge       string
+}
+
+// VerifyPayloadParams is a param in client.VerifyPayload
+type VerifyPayloadParams struct {
+	ManagementToken string
+	Signature       string
+	Body            []byte
+}
+
 // GetUserID returns the user ID of the vehicle owner associated with an Access token.
 func (c *client) GetUserID(ctx context.Context, params *UserIDParams) (*string, error) {
 	target := new(struct {
@@ -149,6 +163,19 @@ func (c *client) HasPermissions(ctx context.Context, v Vehicle, params *Permissi
 	return true, nil
 }
 
+// HashChallenge returns the response to the challenge Smartcar sends when a webhook URL is first verified.
+// Only answer challenges whose request passed VerifyPayload: the response is also the signature of a payload.
+// Note: Does not call Smartcar's API nor makes an http.Request.
+func (c *client) HashChallenge(params *HashChallengeParams) string {
+	return hashChallenge(params.ManagementToken, []byte(params.Challenge))
+}
+
+// VerifyPayload checks that the SC-Signature header of a webhook request matches its body.
+// Note: Does not call Smartcar's API nor makes an http.Request.
+func (c *client) VerifyPayload(params *VerifyPayloadParams) bool {
+	return verifyPayload(params.ManagementToken, params.Signature, params.Body)
+}
+
 // NewVehicle creates an instance of Vehicle that allows you to call methods (i.e. GetInfo, GetOdometer, etc) on it and
 // send requests to Smartcar's API.
 func (c *client) NewVehicle(params *VehicleParams) Vehicle {
@@ -176,6 +203,18 @@ func (c *client) NewAuth(params *AuthParams) Auth {
 	}
 }
 
+// NewWebhookHandler creates a WebhookHandler that can be mounted on the URL of a Smartcar webhook.
+func (c *client) NewWebhookHandler(params *WebhookHandlerParams) WebhookHandler {
+	maxBodyBytes := params.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultWebhookMaxBodyBytes
+	}
+	return &webhookHandler{
+		managementToken: params.ManagementToken,
+		maxBodyBytes:    maxBodyBytes,
+	}
+}
+
 // SetAPIVersion sets version of Smartcar API to use
 func (c *client) SetAPIVersion(version string) {
 	APIVersion = version
@@ -187,7 +226,12 @@ type backendClient interface {
 }
 
 // backend is an internal helper struct that implements Backend.
-type backend struct{}
+type backend struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	// roundTripper is the chain of middlewares requests go through, it is nil without middlewares.
+	roundTripper RoundTripper
+}
 
 // getBackend returns a newly created backend.
 func newBackend() backendClient {
@@ -206,13 +250,21 @@ type Client interface {
 	IsTokenExpired(*TokenExpiredParams) bool
 	IsVINCompatible(context.Context, *VINCompatibleParams) (bool, error)
 	HasPermissions(context.Context, Vehicle, *PermissionsParams) (bool, error)
+	HashChallenge(*HashChallengeParams) string
+	VerifyPayload(*VerifyPayloadParams) bool
 	NewAuth(*AuthParams) Auth
+	NewFleet(*FleetParams) Fleet
 	NewVehicle(*VehicleParams) Vehicle
+	NewWebhookHandler(*WebhookHandlerParams) WebhookHandler
 	SetAPIVersion(string)
 }
 
 // NewClient creates new SmartcarClient. This is the entry point for communicating with Smartcar's API.
 // Note: You cannot use any of the methods on this SDK if you don't call this method.
-func NewClient() Client {
-	return &client{sC: newBackend()}
+func NewClient(opts ...ClientOption) Client {
+	o := &clientOptions{backend: &backend{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &client{sC: o.newBackendClient()}
 }
@@ -73,6 +73,25 @@ func (s *SmartcarTestSuite) TestIsVINCompatible() {
 // 	assert.NotNil(s.T(), res)
 // }
 
+func (s *SmartcarTestSuite) TestHashChallenge() {
+	res := s.client.HashChallenge(&HashChallengeParams{
+		ManagementToken: "amt-1234",
+		Challenge:       "challenge-string",
+	})
+
+	assert.Equal(s.T(), "73317d612d929825620602937d77100446933a49478dca3050c9d2af0027becd", res)
+}
+
+func (s *SmartcarTestSuite) TestVerifyPayload() {
+	res := s.client.VerifyPayload(&VerifyPayloadParams{
+		ManagementToken: "amt-1234",
+		Signature:       "9777e2964ece6fef515d598ed398f24366ba3bda969da2080d6c1aa0d696e381",
+		Body:            []byte(`{"eventName":"schedule"}`),
+	})
+
+	assert.True(s.T(), res)
+}
+
 func (s *SmartcarTestSuite) TestNewVehicleEmpty() {
 	res := s.client.NewVehicle(&VehicleParams{})
 
@@ -103,6 +122,17 @@ func (s *SmartcarTestSuite) TestNewAuth() {
 	assert.NotNil(s.T(), res)
 }
 
+func (s *SmartcarTestSuite) TestNewWebhookHandler() {
+	res := s.client.NewWebhookHandler(&WebhookHandlerParams{ManagementToken: "amt"})
+
+	expectedHandler := &webhookHandler{
+		managementToken: "amt",
+		maxBodyBytes:    defaultWebhookMaxBodyBytes,
+	}
+
+	assert.Equal(s.T(), expectedHandler, res)
+}
+
 func (s *SmartcarTestSuite) TestNewClient() {
 	res := NewClient()
 
@@ -0,0 +1,190 @@
+package smartcarmock
+
+import (
+	"context"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/mock"
+)
+
+// Client is a mock of smartcar.Client. GetUserID and GetVehicleIDs return either a *string and *[]string, or a
+// string and []string.
+type Client struct {
+	mock.Mock
+}
+
+var _ smartcar.Client = (*Client)(nil)
+
+// NewClient creates a Client that fails t on unexpected calls, instead of panicking.
+func NewClient(t mock.TestingT) *Client {
+	m := new(Client)
+	m.Test(t)
+	return m
+}
+
+// GetUserID implements smartcar.Client.
+func (m *Client) GetUserID(ctx context.Context, params *smartcar.UserIDParams) (*string, error) {
+	args := m.Called(ctx, params)
+	if userID, ok := args.Get(0).(string); ok {
+		return &userID, args.Error(1)
+	}
+	userID, _ := args.Get(0).(*string)
+	return userID, args.Error(1)
+}
+
+// GetVehicleIDs implements smartcar.Client.
+func (m *Client) GetVehicleIDs(ctx context.Context, params *smartcar.VehicleIDsParams) (*[]string, error) {
+	args := m.Called(ctx, params)
+	if vehicleIDs, ok := args.Get(0).([]string); ok {
+		return &vehicleIDs, args.Error(1)
+	}
+	vehicleIDs, _ := args.Get(0).(*[]string)
+	return vehicleIDs, args.Error(1)
+}
+
+// IsTokenExpired implements smartcar.Client.
+func (m *Client) IsTokenExpired(params *smartcar.TokenExpiredParams) bool {
+	args := m.Called(params)
+	return args.Bool(0)
+}
+
+// IsVINCompatible implements smartcar.Client.
+func (m *Client) IsVINCompatible(ctx context.Context, params *smartcar.VINCompatibleParams) (bool, error) {
+	args := m.Called(ctx, params)
+	return args.Bool(0), args.Error(1)
+}
+
+// HasPermissions implements smartcar.Client.
+func (m *Client) HasPermissions(ctx context.Context, v smartcar.Vehicle, params *smartcar.PermissionsParams) (bool, error) {
+	args := m.Called(ctx, v, params)
+	return args.Bool(0), args.Error(1)
+}
+
+// HashChallenge implements smartcar.Client.
+func (m *Client) HashChallenge(params *smartcar.HashChallengeParams) string {
+	args := m.Called(params)
+	return args.String(0)
+}
+
+// VerifyPayload implements smartcar.Client.
+func (m *Client) VerifyPayload(params *smartcar.VerifyPayloadParams) bool {
+	args := m.Called(params)
+	return args.Bool(0)
+}
+
+// NewAuth implements smartcar.Client.
+func (m *Client) NewAuth(params *smartcar.AuthParams) smartcar.Auth {
+	args := m.Called(params)
+	auth, _ := args.Get(0).(smartcar.Auth)
+	return auth
+}
+
+// NewFleet implements smartcar.Client.
+func (m *Client) NewFleet(params *smartcar.FleetParams) smartcar.Fleet {
+	args := m.Called(params)
+	fleet, _ := args.Get(0).(smartcar.Fleet)
+	return fleet
+}
+
+// NewVehicle implements smartcar.Client.
+func (m *Client) NewVehicle(params *smartcar.VehicleParams) smartcar.Vehicle {
+	args := m.Called(params)
+	vehicle, _ := args.Get(0).(smartcar.Vehicle)
+	return vehicle
+}
+
+// NewWebhookHandler implements smartcar.Client.
+func (m *Client) NewWebhookHandler(params *smartcar.WebhookHandlerParams) smartcar.WebhookHandler {
+	args := m.Called(params)
+	handler, _ := args.Get(0).(smartcar.WebhookHandler)
+	return handler
+}
+
+// SetAPIVersion implements smartcar.Client.
+func (m *Client) SetAPIVersion(version string) {
+	m.Called(version)
+}
+
+// Auth is a mock of smartcar.Auth.
+type Auth struct {
+	mock.Mock
+}
+
+var _ smartcar.Auth = (*Auth)(nil)
+
+// NewAuth creates an Auth that fails t on unexpected calls, instead of panicking.
+func NewAuth(t mock.TestingT) *Auth {
+	m := new(Auth)
+	m.Test(t)
+	return m
+}
+
+// GetAuthURL implements smartcar.Auth.
+func (m *Auth) GetAuthURL(params *smartcar.AuthURLParams) (string, error) {
+	args := m.Called(params)
+	return args.String(0), args.Error(1)
+}
+
+// ExchangeCode implements smartcar.Auth.
+func (m *Auth) ExchangeCode(ctx context.Context, params *smartcar.ExchangeCodeParams) (*smartcar.Token, error) {
+	args := m.Called(ctx, params)
+	token, _ := args.Get(0).(*smartcar.Token)
+	return token, args.Error(1)
+}
+
+// ExchangeRefreshToken implements smartcar.Auth.
+func (m *Auth) ExchangeRefreshToken(ctx context.Context, params *smartcar.ExchangeRefreshTokenParams) (*smartcar.Token, error) {
+	args := m.Called(ctx, params)
+	token, _ := args.Get(0).(*smartcar.Token)
+	return token, args.Error(1)
+}
+
+// Fleet is a mock of smartcar.Fleet. Do and Batch return a <-chan *smartcar.FleetResult, or a slice of results
+// that are sent on a new channel.
+type Fleet struct {
+	mock.Mock
+}
+
+var _ smartcar.Fleet = (*Fleet)(nil)
+
+// NewFleet creates a Fleet that fails t on unexpected calls, instead of panicking.
+func NewFleet(t mock.TestingT) *Fleet {
+	m := new(Fleet)
+	m.Test(t)
+	return m
+}
+
+// Do implements smartcar.Fleet.
+func (m *Fleet) Do(ctx context.Context, fn smartcar.FleetFunc) <-chan *smartcar.FleetResult {
+	return fleetResults(m.Called(ctx, fn))
+}
+
+// Batch implements smartcar.Fleet.
+func (m *Fleet) Batch(ctx context.Context, paths ...smartcar.Key) <-chan *smartcar.FleetResult {
+	arguments := []interface{}{ctx}
+	for _, path := range paths {
+		arguments = append(arguments, path)
+	}
+	return fleetResults(m.Called(arguments...))
+}
+
+// fleetResults returns the results of a call to a Fleet. Results returned as a slice, or as nil, are sent on a
+// channel that is closed after them.
+func fleetResults(args mock.Arguments) <-chan *smartcar.FleetResult {
+	switch results := args.Get(0).(type) {
+	case <-chan *smartcar.FleetResult:
+		return results
+	case chan *smartcar.FleetResult:
+		return results
+	case []*smartcar.FleetResult:
+		ch := make(chan *smartcar.FleetResult, len(results))
+		for _, result := range results {
+			ch <- result
+		}
+		close(ch)
+		return ch
+	}
+	ch := make(chan *smartcar.FleetResult)
+	close(ch)
+	return ch
+}
@@ -0,0 +1,116 @@
+package smartcarmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestVehicleReturns(t *testing.T) {
+	// Arrange
+	vehicle := NewVehicle(t)
+	asleep := errors.New("vehicle is asleep")
+	vehicle.On("GetOdometer", mock.Anything).Return(nil, asleep).Once()
+	vehicle.On("GetOdometer", mock.Anything).Return(&smartcar.Odometer{Distance: smartcar.Distance{Value: 1000}}, nil)
+
+	// Act
+	_, err := vehicle.GetOdometer(context.Background())
+	odometer, retryErr := vehicle.GetOdometer(context.Background())
+
+	// Assert
+	assert.Equal(t, asleep, err)
+	assert.Nil(t, retryErr)
+	assert.Equal(t, 1000.0, odometer.Distance.Value)
+	vehicle.AssertExpectations(t)
+	vehicle.AssertNumberOfCalls(t, "GetOdometer", 2)
+}
+
+func TestVehicleOptionsAndPaths(t *testing.T) {
+	// Arrange
+	vehicle := NewVehicle(t)
+	vehicle.On("GetBattery", mock.Anything, mock.Anything).Return(&smartcar.Battery{PercentRemaining: 0.5}, nil)
+	vehicle.On("Batch", mock.Anything, smartcar.OdometerPath, smartcar.LocationPath).Return(&smartcar.Data{}, nil)
+	vehicle.On("BatchWithOptions", mock.Anything, []smartcar.Key{smartcar.OdometerPath}, mock.Anything).Return(&smartcar.Data{}, nil)
+
+	// Act
+	battery, err := vehicle.GetBattery(context.Background(), smartcar.WithUnits(smartcar.Imperial))
+	_, batchErr := vehicle.Batch(context.Background(), smartcar.OdometerPath, smartcar.LocationPath)
+	_, optionsErr := vehicle.BatchWithOptions(context.Background(), []smartcar.Key{smartcar.OdometerPath}, smartcar.WithMaxAge(time.Minute))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, battery.PercentRemaining)
+	assert.Nil(t, batchErr)
+	assert.Nil(t, optionsErr)
+	vehicle.AssertCalled(t, "Batch", mock.Anything, smartcar.OdometerPath, smartcar.LocationPath)
+	vehicle.AssertNotCalled(t, "Lock", mock.Anything)
+}
+
+func TestClient(t *testing.T) {
+	// Arrange
+	client := NewClient(t)
+	vehicle := NewVehicle(t)
+	client.On("GetUserID", mock.Anything, &smartcar.UserIDParams{Access: "token"}).Return("user-id", nil)
+	client.On("GetVehicleIDs", mock.Anything, mock.Anything).Return([]string{"vehicle-id"}, nil)
+	client.On("NewVehicle", mock.MatchedBy(func(params *smartcar.VehicleParams) bool {
+		return params.ID == "vehicle-id"
+	})).Return(vehicle)
+	vehicle.On("Lock", mock.Anything).Return(&smartcar.Security{Status: "success"}, nil)
+
+	// Act
+	userID, err := client.GetUserID(context.Background(), &smartcar.UserIDParams{Access: "token"})
+	vehicleIDs, _ := client.GetVehicleIDs(context.Background(), &smartcar.VehicleIDsParams{Access: "token"})
+	lock, lockErr := client.NewVehicle(&smartcar.VehicleParams{ID: (*vehicleIDs)[0]}).Lock(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "user-id", *userID)
+	assert.Nil(t, lockErr)
+	assert.Equal(t, "success", lock.Status)
+	client.AssertExpectations(t)
+	vehicle.AssertExpectations(t)
+}
+
+func TestAuth(t *testing.T) {
+	// Arrange
+	auth := NewAuth(t)
+	auth.On("ExchangeCode", mock.Anything, &smartcar.ExchangeCodeParams{Code: "code"}).Return(&smartcar.Token{Access: "access"}, nil)
+	auth.On("ExchangeCode", mock.Anything, mock.Anything).Return(nil, errors.New("invalid code"))
+
+	// Act
+	token, err := auth.ExchangeCode(context.Background(), &smartcar.ExchangeCodeParams{Code: "code"})
+	invalid, invalidErr := auth.ExchangeCode(context.Background(), &smartcar.ExchangeCodeParams{Code: "other"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "access", token.Access)
+	assert.Nil(t, invalid)
+	assert.EqualError(t, invalidErr, "invalid code")
+}
+
+func TestFleet(t *testing.T) {
+	// Arrange
+	fleet := NewFleet(t)
+	fleet.On("Batch", mock.Anything, smartcar.OdometerPath).Return([]*smartcar.FleetResult{
+		{VehicleID: "v1", Value: &smartcar.Data{}},
+		{VehicleID: "v2", Err: errors.New("asleep")},
+	})
+	fleet.On("Do", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	var results []*smartcar.FleetResult
+	for result := range fleet.Batch(context.Background(), smartcar.OdometerPath) {
+		results = append(results, result)
+	}
+	_, open := <-fleet.Do(context.Background(), nil)
+
+	// Assert
+	assert.Len(t, results, 2)
+	assert.Equal(t, "v2", results[1].VehicleID)
+	assert.False(t, open)
+}
@@ -0,0 +1,247 @@
+// Package smartcarmock provides mocks of the smartcar.Vehicle, smartcar.Auth, smartcar.Client and smartcar.Fleet
+// interfaces for unit tests, built on github.com/stretchr/testify/mock. Every call is recorded with its arguments,
+// and the values it returns are set with On and Return:
+//
+//	vehicle := smartcarmock.NewVehicle(t)
+//	vehicle.On("GetOdometer", mock.Anything).Return(&smartcar.Odometer{}, nil).Once()
+//	vehicle.On("Lock", mock.Anything).Return(nil, errors.New("asleep"))
+//	...
+//	vehicle.AssertExpectations(t)
+//
+// Request options are recorded as extra arguments after the context, so a call with one option is matched by
+// On("GetOdometer", mock.Anything, mock.Anything). Results that are nil can be returned as untyped nil.
+package smartcarmock
+
+import (
+	"context"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/mock"
+)
+
+// Vehicle is a mock of smartcar.Vehicle.
+type Vehicle struct {
+	mock.Mock
+}
+
+var _ smartcar.Vehicle = (*Vehicle)(nil)
+
+// NewVehicle creates a Vehicle that fails t on unexpected calls, instead of panicking.
+func NewVehicle(t mock.TestingT) *Vehicle {
+	m := new(Vehicle)
+	m.Test(t)
+	return m
+}
+
+// withOptions returns the recorded arguments of a call with request options.
+func withOptions(ctx context.Context, opts []smartcar.RequestOption) []interface{} {
+	arguments := []interface{}{ctx}
+	for _, opt := range opts {
+		arguments = append(arguments, opt)
+	}
+	return arguments
+}
+
+// Batch implements smartcar.Vehicle.
+func (m *Vehicle) Batch(ctx context.Context, paths ...smartcar.Key) (*smartcar.Data, error) {
+	arguments := []interface{}{ctx}
+	for _, path := range paths {
+		arguments = append(arguments, path)
+	}
+	args := m.Called(arguments...)
+	data, _ := args.Get(0).(*smartcar.Data)
+	return data, args.Error(1)
+}
+
+// BatchWithOptions implements smartcar.Vehicle. The paths are recorded as a single []smartcar.Key argument,
+// followed by the request options.
+func (m *Vehicle) BatchWithOptions(ctx context.Context, paths []smartcar.Key, opts ...smartcar.RequestOption) (*smartcar.Data, error) {
+	arguments := []interface{}{ctx, paths}
+	for _, opt := range opts {
+		arguments = append(arguments, opt)
+	}
+	args := m.Called(arguments...)
+	data, _ := args.Get(0).(*smartcar.Data)
+	return data, args.Error(1)
+}
+
+// Disconnect implements smartcar.Vehicle.
+func (m *Vehicle) Disconnect(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Disconnect, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	disconnect, _ := args.Get(0).(*smartcar.Disconnect)
+	return disconnect, args.Error(1)
+}
+
+// GetBattery implements smartcar.Vehicle.
+func (m *Vehicle) GetBattery(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Battery, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	battery, _ := args.Get(0).(*smartcar.Battery)
+	return battery, args.Error(1)
+}
+
+// GetBatteryCapacity implements smartcar.Vehicle.
+func (m *Vehicle) GetBatteryCapacity(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.BatteryCapacity, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	batteryCapacity, _ := args.Get(0).(*smartcar.BatteryCapacity)
+	return batteryCapacity, args.Error(1)
+}
+
+// GetCharge implements smartcar.Vehicle.
+func (m *Vehicle) GetCharge(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Charge, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	charge, _ := args.Get(0).(*smartcar.Charge)
+	return charge, args.Error(1)
+}
+
+// GetChargeAmperage implements smartcar.Vehicle.
+func (m *Vehicle) GetChargeAmperage(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargeAmperage, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargeAmperage, _ := args.Get(0).(*smartcar.ChargeAmperage)
+	return chargeAmperage, args.Error(1)
+}
+
+// GetChargeCompletion implements smartcar.Vehicle.
+func (m *Vehicle) GetChargeCompletion(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargeCompletion, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargeCompletion, _ := args.Get(0).(*smartcar.ChargeCompletion)
+	return chargeCompletion, args.Error(1)
+}
+
+// GetChargeEnergyAdded implements smartcar.Vehicle.
+func (m *Vehicle) GetChargeEnergyAdded(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargeEnergyAdded, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargeEnergyAdded, _ := args.Get(0).(*smartcar.ChargeEnergyAdded)
+	return chargeEnergyAdded, args.Error(1)
+}
+
+// GetChargeRate implements smartcar.Vehicle.
+func (m *Vehicle) GetChargeRate(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargeRate, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargeRate, _ := args.Get(0).(*smartcar.ChargeRate)
+	return chargeRate, args.Error(1)
+}
+
+// GetChargeVoltage implements smartcar.Vehicle.
+func (m *Vehicle) GetChargeVoltage(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargeVoltage, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargeVoltage, _ := args.Get(0).(*smartcar.ChargeVoltage)
+	return chargeVoltage, args.Error(1)
+}
+
+// GetChargerType implements smartcar.Vehicle.
+func (m *Vehicle) GetChargerType(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargerType, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargerType, _ := args.Get(0).(*smartcar.ChargerType)
+	return chargerType, args.Error(1)
+}
+
+// GetFuel implements smartcar.Vehicle.
+func (m *Vehicle) GetFuel(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Fuel, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	fuel, _ := args.Get(0).(*smartcar.Fuel)
+	return fuel, args.Error(1)
+}
+
+// GetIgnition implements smartcar.Vehicle.
+func (m *Vehicle) GetIgnition(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Ignition, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	ignition, _ := args.Get(0).(*smartcar.Ignition)
+	return ignition, args.Error(1)
+}
+
+// GetInfo implements smartcar.Vehicle.
+func (m *Vehicle) GetInfo(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Info, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	info, _ := args.Get(0).(*smartcar.Info)
+	return info, args.Error(1)
+}
+
+// GetLocation implements smartcar.Vehicle.
+func (m *Vehicle) GetLocation(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Location, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	location, _ := args.Get(0).(*smartcar.Location)
+	return location, args.Error(1)
+}
+
+// GetOdometer implements smartcar.Vehicle.
+func (m *Vehicle) GetOdometer(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Odometer, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	odometer, _ := args.Get(0).(*smartcar.Odometer)
+	return odometer, args.Error(1)
+}
+
+// GetOil implements smartcar.Vehicle.
+func (m *Vehicle) GetOil(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Oil, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	oil, _ := args.Get(0).(*smartcar.Oil)
+	return oil, args.Error(1)
+}
+
+// GetPermissions implements smartcar.Vehicle.
+func (m *Vehicle) GetPermissions(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Permissions, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	permissions, _ := args.Get(0).(*smartcar.Permissions)
+	return permissions, args.Error(1)
+}
+
+// GetTiresPressure implements smartcar.Vehicle.
+func (m *Vehicle) GetTiresPressure(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.TirePressure, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	tirePressure, _ := args.Get(0).(*smartcar.TirePressure)
+	return tirePressure, args.Error(1)
+}
+
+// GetVIN implements smartcar.Vehicle.
+func (m *Vehicle) GetVIN(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.VIN, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	vin, _ := args.Get(0).(*smartcar.VIN)
+	return vin, args.Error(1)
+}
+
+// Lock implements smartcar.Vehicle.
+func (m *Vehicle) Lock(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Security, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	security, _ := args.Get(0).(*smartcar.Security)
+	return security, args.Error(1)
+}
+
+// SetUnitSystem implements smartcar.Vehicle.
+func (m *Vehicle) SetUnitSystem(params *smartcar.UnitsParams) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+// Subscribe implements smartcar.Vehicle.
+func (m *Vehicle) Subscribe(ctx context.Context, webhookID string) (*smartcar.Subscribe, error) {
+	args := m.Called(ctx, webhookID)
+	subscribe, _ := args.Get(0).(*smartcar.Subscribe)
+	return subscribe, args.Error(1)
+}
+
+// Unlock implements smartcar.Vehicle.
+func (m *Vehicle) Unlock(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.Security, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	security, _ := args.Get(0).(*smartcar.Security)
+	return security, args.Error(1)
+}
+
+// Unsubscribe implements smartcar.Vehicle.
+func (m *Vehicle) Unsubscribe(ctx context.Context, managementToken, webhookID string) (*smartcar.Unsubscribe, error) {
+	args := m.Called(ctx, managementToken, webhookID)
+	unsubscribe, _ := args.Get(0).(*smartcar.Unsubscribe)
+	return unsubscribe, args.Error(1)
+}
+
+// StartCharge implements smartcar.Vehicle.
+func (m *Vehicle) StartCharge(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargeControl, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargeControl, _ := args.Get(0).(*smartcar.ChargeControl)
+	return chargeControl, args.Error(1)
+}
+
+// StopCharge implements smartcar.Vehicle.
+func (m *Vehicle) StopCharge(ctx context.Context, opts ...smartcar.RequestOption) (*smartcar.ChargeControl, error) {
+	args := m.Called(withOptions(ctx, opts)...)
+	chargeControl, _ := args.Get(0).(*smartcar.ChargeControl)
+	return chargeControl, args.Error(1)
+}
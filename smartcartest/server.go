@@ -0,0 +1,611 @@
+// Package smartcartest provides an in-process fake of Smartcar's API for tests. It serves the vehicle, batch,
+// user, compatibility and token endpoints from an in-memory fleet of simulated vehicles, whose state changes with
+// the commands they receive and as time passes, and can be made to fail on demand.
+package smartcartest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// Paths of the endpoints that are not vehicle endpoints, to use in FailParams.
+const (
+	UserPath          smartcar.Key = "/user"
+	VehiclesPath      smartcar.Key = "/vehicles"
+	CompatibilityPath smartcar.Key = "/compatibility"
+	TokenPath         smartcar.Key = "/oauth/token"
+)
+
+// Paths of the vehicle endpoints that smartcar does not export.
+const (
+	securityPath      smartcar.Key = "/security"
+	chargeControlPath smartcar.Key = "/charge"
+	applicationPath   smartcar.Key = "/application"
+	batchPath         smartcar.Key = "/batch"
+	webhooksPath      smartcar.Key = "/webhooks/"
+)
+
+// tokenExpiresIn is the lifetime of the access tokens, in seconds.
+const tokenExpiresIn = 7200
+
+// User is a user of the application, who connected the vehicles with VehicleIDs.
+type User struct {
+	ID           string
+	AccessToken  string
+	RefreshToken string
+	// Code is the authorization code exchanged for the tokens of the user by auth.ExchangeCode. It can be
+	// exchanged once.
+	Code       string
+	VehicleIDs []string
+}
+
+// ServerParams is a param in smartcartest.NewServer
+type ServerParams struct {
+	// Vehicles that are not in the VehicleIDs of any of the Users are given their own user, with the ID
+	// "user-<vehicle ID>" and the access token "access-<vehicle ID>".
+	Vehicles []*Vehicle
+	Users    []*User
+	// ClientID and ClientSecret are checked by the token and compatibility endpoints when they are set, and
+	// ManagementToken by the webhook unsubscribe endpoint.
+	ClientID        string
+	ClientSecret    string
+	ManagementToken string
+	// Now returns the current time, it defaults to time.Now. The state of the vehicles evolves as it advances, use
+	// a Clock to control it.
+	Now func() time.Time
+}
+
+// FailParams is a param in server.Fail
+type FailParams struct {
+	// VehicleID and Path select the requests that fail, they match every vehicle and every path when they are
+	// empty. Path is the path of a vehicle endpoint, i.e. smartcar.BatteryPath, or one of UserPath, VehiclesPath,
+	// CompatibilityPath or TokenPath. The paths of a batch request fail in the batch response.
+	VehicleID string
+	Path      smartcar.Key
+	// Error is the response of the failing requests. Its RetryAfter is sent in the Retry-After header.
+	Error *smartcar.Error
+	// Times is how many requests fail. When it is 0 they fail until server.ClearFailures is called.
+	Times int
+}
+
+// simulatedVehicle is a vehicle of the server.
+type simulatedVehicle struct {
+	Vehicle
+	// updated is the time up to which the vehicle was simulated.
+	updated time.Time
+}
+
+// Server is a fake of Smartcar's API. It is an httptest.Server, send requests to it with a client created by
+// server.NewClient, or by smartcar.NewClient with the smartcar.WithBaseURL and smartcar.WithHTTPClient options.
+type Server struct {
+	*httptest.Server
+	clientID        string
+	clientSecret    string
+	managementToken string
+	now             func() time.Time
+	client          smartcar.Client
+
+	mu        sync.Mutex
+	vehicles  map[string]*simulatedVehicle
+	users     []*User
+	failures  []*FailParams
+	requestID int
+}
+
+// NewServer starts a Server, it must be closed with server.Close.
+func NewServer(params *ServerParams) *Server {
+	s := &Server{
+		clientID:        params.ClientID,
+		clientSecret:    params.ClientSecret,
+		managementToken: params.ManagementToken,
+		now:             params.Now,
+		vehicles:        make(map[string]*simulatedVehicle),
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+
+	owned := make(map[string]bool)
+	for _, u := range params.Users {
+		user := *u
+		user.VehicleIDs = append([]string(nil), u.VehicleIDs...)
+		s.users = append(s.users, &user)
+		for _, id := range u.VehicleIDs {
+			owned[id] = true
+		}
+	}
+	for _, v := range params.Vehicles {
+		vehicle := &simulatedVehicle{Vehicle: *v, updated: s.now()}
+		vehicle.Route = append([]Waypoint(nil), v.Route...)
+		vehicle.setDefaults()
+		s.vehicles[v.ID] = vehicle
+		if !owned[v.ID] {
+			s.users = append(s.users, &User{
+				ID:           "user-" + v.ID,
+				AccessToken:  "access-" + v.ID,
+				RefreshToken: "refresh-" + v.ID,
+				VehicleIDs:   []string{v.ID},
+			})
+		}
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.client = s.NewClient()
+	return s
+}
+
+// NewClient returns a smartcar.Client that sends its requests to the server.
+func (s *Server) NewClient(opts ...smartcar.ClientOption) smartcar.Client {
+	opts = append([]smartcar.ClientOption{smartcar.WithBaseURL(s.URL), smartcar.WithHTTPClient(s.Client())}, opts...)
+	return smartcar.NewClient(opts...)
+}
+
+// NewVehicle returns a smartcar.Vehicle for a vehicle of the server, authorized with the access token of its user.
+func (s *Server) NewVehicle(vehicleID string) smartcar.Vehicle {
+	s.mu.Lock()
+	user := s.owner(vehicleID)
+	s.mu.Unlock()
+
+	var accessToken string
+	if user != nil {
+		accessToken = user.AccessToken
+	}
+	return s.client.NewVehicle(&smartcar.VehicleParams{ID: vehicleID, AccessToken: accessToken})
+}
+
+// Vehicle returns a copy of the current state of a vehicle, or nil when the server has no such vehicle.
+func (s *Server) Vehicle(vehicleID string) *Vehicle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.vehicle(vehicleID)
+	if v == nil {
+		return nil
+	}
+	vehicle := v.Vehicle
+	vehicle.Route = append([]Waypoint(nil), v.Route...)
+	return &vehicle
+}
+
+// UpdateVehicle changes the state of a vehicle with fn. It returns false when the server has no such vehicle.
+func (s *Server) UpdateVehicle(vehicleID string, fn func(*Vehicle)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.vehicle(vehicleID)
+	if v == nil {
+		return false
+	}
+	fn(&v.Vehicle)
+	return true
+}
+
+// User returns a copy of a user, or nil when the server has no such user.
+func (s *Server) User(userID string) *User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.ID == userID {
+			user := *u
+			user.VehicleIDs = append([]string(nil), u.VehicleIDs...)
+			return &user
+		}
+	}
+	return nil
+}
+
+// Fail makes the requests selected by params fail. Failures are matched in the order they were added.
+func (s *Server) Fail(params *FailParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failure := *params
+	s.failures = append(s.failures, &failure)
+}
+
+// ClearFailures removes every failure added with server.Fail.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// vehicle returns a vehicle simulated up to now, or nil. s.mu must be held.
+func (s *Server) vehicle(vehicleID string) *simulatedVehicle {
+	v, ok := s.vehicles[vehicleID]
+	if !ok {
+		return nil
+	}
+	now := s.now()
+	if now.After(v.updated) {
+		v.simulate(now.Sub(v.updated))
+		v.updated = now
+	}
+	return v
+}
+
+// owner returns the user of a vehicle, or nil. s.mu must be held.
+func (s *Server) owner(vehicleID string) *User {
+	for _, u := range s.users {
+		for _, id := range u.VehicleIDs {
+			if id == vehicleID {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// failure returns the error of the first failure matching a request, or nil. s.mu must be held.
+func (s *Server) failure(vehicleID string, path smartcar.Key) *smartcar.Error {
+	for i, f := range s.failures {
+		if (f.VehicleID != "" && f.VehicleID != vehicleID) || (f.Path != "" && f.Path != path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f.Error
+	}
+	return nil
+}
+
+// serveHTTP routes a request to the handler of its endpoint.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestID++
+	w.Header().Set("Sc-Request-Id", strconv.Itoa(s.requestID))
+
+	if strings.TrimSuffix(r.URL.Path, "/") == string(TokenPath) {
+		s.serveToken(w, r)
+		return
+	}
+
+	// API paths are /v<version>/<resource>[/<vehicle ID><vehicle path>]
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "v") {
+		writeError(w, notFound())
+		return
+	}
+	rest := ""
+	if len(parts) == 3 {
+		rest = parts[2]
+	}
+	switch {
+	case parts[1] == "user" && rest == "":
+		s.serveUser(w, r)
+	case parts[1] == "vehicles" && rest == "":
+		s.serveVehicles(w, r)
+	case parts[1] == "compatibility" && rest == "":
+		s.serveCompatibility(w, r)
+	case parts[1] == "vehicles":
+		vehicleID, path := rest, "/"
+		if i := strings.Index(rest, "/"); i >= 0 {
+			vehicleID, path = rest[:i], rest[i:]
+		}
+		s.serveVehicle(w, r, vehicleID, smartcar.Key(path))
+	default:
+		writeError(w, notFound())
+	}
+}
+
+// serveToken exchanges authorization codes and refresh tokens.
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := s.failure("", TokenPath); err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.authorizedClient(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error":             "invalid_client",
+			"error_description": "Invalid client credentials.",
+		})
+		return
+	}
+
+	var user *User
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		for _, u := range s.users {
+			if u.Code != "" && u.Code == r.PostFormValue("code") {
+				user = u
+				user.Code = ""
+			}
+		}
+	case "refresh_token":
+		for _, u := range s.users {
+			if u.RefreshToken != "" && u.RefreshToken == r.PostFormValue("refresh_token") {
+				user = u
+				user.AccessToken, user.RefreshToken = newToken(), newToken()
+			}
+		}
+	}
+	if user == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "Invalid or expired authorization code or refresh token.",
+		})
+		return
+	}
+	if user.AccessToken == "" {
+		user.AccessToken, user.RefreshToken = newToken(), newToken()
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  user.AccessToken,
+		"token_type":    "Bearer",
+		"expires_in":    tokenExpiresIn,
+		"refresh_token": user.RefreshToken,
+	})
+}
+
+// serveUser returns the ID of the user of an access token.
+func (s *Server) serveUser(w http.ResponseWriter, r *http.Request) {
+	user, err := s.authorizedUser(r, UserPath)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": user.ID})
+}
+
+// serveVehicles returns the IDs of the vehicles of the user of an access token.
+func (s *Server) serveVehicles(w http.ResponseWriter, r *http.Request) {
+	user, err := s.authorizedUser(r, VehiclesPath)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	vehicleIDs := append([]string{}, user.VehicleIDs...)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"vehicles": vehicleIDs,
+		"paging":   map[string]int{"count": len(vehicleIDs), "offset": 0},
+	})
+}
+
+// serveCompatibility reports a VIN as compatible when one of the vehicles of the server has it.
+func (s *Server) serveCompatibility(w http.ResponseWriter, r *http.Request) {
+	if err := s.failure("", CompatibilityPath); err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.authorizedClient(r) {
+		writeError(w, authenticationError())
+		return
+	}
+	vin := r.URL.Query().Get("vin")
+	compatible := false
+	for _, v := range s.vehicles {
+		if vin != "" && v.VIN == vin {
+			compatible = true
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"compatible": compatible})
+}
+
+// serveVehicle serves the endpoints of a vehicle.
+func (s *Server) serveVehicle(w http.ResponseWriter, r *http.Request, vehicleID string, path smartcar.Key) {
+	// Unsubscribing is authorized with the management token of the application instead of the user's token.
+	if r.Method == http.MethodDelete && strings.HasPrefix(string(path), string(webhooksPath)) {
+		if s.managementToken != "" && r.Header.Get("Authorization") != "Bearer "+s.managementToken {
+			writeError(w, authenticationError())
+			return
+		}
+		writeJSON(w, http.StatusOK, &smartcar.Unsubscribe{Status: "success"})
+		return
+	}
+
+	user, err := s.authorizedUser(r, "")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	v := s.vehicle(vehicleID)
+	if v == nil || s.owner(vehicleID) != user {
+		writeError(w, notFound())
+		return
+	}
+	if err := s.failure(vehicleID, path); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	units := smartcar.Metric
+	if smartcar.UnitSystem(r.Header.Get("Sc-Unit-System")) == smartcar.Imperial {
+		units = smartcar.Imperial
+	}
+	w.Header().Set("Sc-Unit-System", string(units))
+	w.Header().Set("Sc-Data-Age", s.now().UTC().Format(time.RFC3339))
+
+	switch {
+	case r.Method == http.MethodGet:
+		response := v.read(path, units)
+		if response == nil {
+			writeError(w, notFound())
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	case r.Method == http.MethodPost && path == batchPath:
+		s.serveBatch(w, r, v, units)
+	case r.Method == http.MethodPost && path == securityPath:
+		serveCommand(w, r, map[string]func() *smartcar.Error{
+			"LOCK":   func() *smartcar.Error { v.Locked = true; return nil },
+			"UNLOCK": func() *smartcar.Error { v.Locked = false; return nil },
+		})
+	case r.Method == http.MethodPost && path == chargeControlPath:
+		serveCommand(w, r, map[string]func() *smartcar.Error{
+			"START": func() *smartcar.Error {
+				if !v.PluggedIn {
+					return &smartcar.Error{
+						StatusCode: http.StatusConflict,
+						Type:       "VEHICLE_STATE",
+						Code:       "CHARGING_PLUG_NOT_CONNECTED",
+						Message:    "The vehicle is not plugged in.",
+					}
+				}
+				if !v.Charging {
+					v.Charging, v.EnergyAdded = true, 0
+				}
+				return nil
+			},
+			"STOP": func() *smartcar.Error { v.Charging = false; return nil },
+		})
+	case r.Method == http.MethodPost && strings.HasPrefix(string(path), string(webhooksPath)):
+		webhookID := strings.TrimPrefix(string(path), string(webhooksPath))
+		writeJSON(w, http.StatusOK, &smartcar.Subscribe{WebhookID: webhookID, VehicleID: vehicleID})
+	case r.Method == http.MethodDelete && path == applicationPath:
+		for i, id := range user.VehicleIDs {
+			if id == vehicleID {
+				user.VehicleIDs = append(user.VehicleIDs[:i], user.VehicleIDs[i+1:]...)
+				break
+			}
+		}
+		writeJSON(w, http.StatusOK, &smartcar.Disconnect{Status: "success"})
+	default:
+		writeError(w, notFound())
+	}
+}
+
+// serveBatch serves a batch request, the failures of its paths are reported in the batch response.
+func (s *Server) serveBatch(w http.ResponseWriter, r *http.Request, v *simulatedVehicle, units smartcar.UnitSystem) {
+	batch := new(struct {
+		Requests []struct {
+			Path smartcar.Key `json:"path"`
+		} `json:"requests"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(batch); err != nil {
+		writeError(w, validationError("The body of the batch request is invalid."))
+		return
+	}
+
+	type batchItem struct {
+		Path    smartcar.Key      `json:"path"`
+		Code    int               `json:"code"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    interface{}       `json:"body"`
+	}
+	headers := map[string]string{
+		"sc-unit-system": string(units),
+		"sc-data-age":    s.now().UTC().Format(time.RFC3339),
+	}
+	responses := []batchItem{}
+	for _, request := range batch.Requests {
+		item := batchItem{Path: request.Path, Code: http.StatusOK, Headers: headers}
+		if err := s.failure(v.ID, request.Path); err != nil {
+			item.Code, item.Headers, item.Body = err.StatusCode, nil, errorBody(err)
+		} else if item.Body = v.read(request.Path, units); item.Body == nil {
+			err := notFound()
+			item.Code, item.Headers, item.Body = err.StatusCode, nil, errorBody(err)
+		}
+		responses = append(responses, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"responses": responses})
+}
+
+// serveCommand runs the action of a command request.
+func serveCommand(w http.ResponseWriter, r *http.Request, actions map[string]func() *smartcar.Error) {
+	command := new(struct {
+		Action string `json:"action"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(command); err != nil {
+		writeError(w, validationError("The body of the command is invalid."))
+		return
+	}
+	action, ok := actions[command.Action]
+	if !ok {
+		writeError(w, validationError("Unknown action "+command.Action+"."))
+		return
+	}
+	if err := action(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// authorizedUser returns the user of the bearer token of a request.
+func (s *Server) authorizedUser(r *http.Request, path smartcar.Key) (*User, *smartcar.Error) {
+	if path != "" {
+		if err := s.failure("", path); err != nil {
+			return nil, err
+		}
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	for _, u := range s.users {
+		if token != "" && u.AccessToken == token {
+			return u, nil
+		}
+	}
+	return nil, authenticationError()
+}
+
+// authorizedClient checks the basic credentials of a request, when the server has them.
+func (s *Server) authorizedClient(r *http.Request) bool {
+	if s.clientID == "" && s.clientSecret == "" {
+		return true
+	}
+	id, secret, ok := r.BasicAuth()
+	return ok && id == s.clientID && secret == s.clientSecret
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error response in the format of Smartcar's API.
+func writeError(w http.ResponseWriter, err *smartcar.Error) {
+	if err.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	}
+	writeJSON(w, err.StatusCode, errorBody(err))
+}
+
+// errorBody returns the body of an error response in the format of version 2.0 of Smartcar's API.
+func errorBody(err *smartcar.Error) map[string]interface{} {
+	return map[string]interface{}{
+		"statusCode":  err.StatusCode,
+		"type":        err.Type,
+		"code":        err.Code,
+		"description": err.Message,
+	}
+}
+
+func authenticationError() *smartcar.Error {
+	return &smartcar.Error{
+		StatusCode: http.StatusUnauthorized,
+		Type:       "AUTHENTICATION",
+		Message:    "The authorization header is missing or malformed, or it contains invalid or expired credentials.",
+	}
+}
+
+func notFound() *smartcar.Error {
+	return &smartcar.Error{
+		StatusCode: http.StatusNotFound,
+		Type:       "RESOURCE_NOT_FOUND",
+		Message:    "The requested resource does not exist.",
+	}
+}
+
+func validationError(message string) *smartcar.Error {
+	return &smartcar.Error{StatusCode: http.StatusBadRequest, Type: "VALIDATION", Message: message}
+}
+
+// newToken returns a random token.
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
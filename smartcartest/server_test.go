@@ -0,0 +1,241 @@
+package smartcartest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServerTestSuite struct {
+	suite.Suite
+	server *Server
+	client smartcar.Client
+	now    time.Time
+}
+
+func (s *ServerTestSuite) SetupTest() {
+	s.now = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.server = NewServer(&ServerParams{
+		Vehicles: []*Vehicle{
+			{ID: "v1", VIN: "5YJ3E1EA1JF000001", Make: "TESLA", Model: "Model 3", Year: 2019, Odometer: 1000, PercentRemaining: 0.5},
+			{ID: "v2", VIN: "1FTFW1E50JF000002", Make: "FORD", PluggedIn: true, PercentRemaining: 0.2},
+			{ID: "v3", Make: "BMW"},
+		},
+		Users: []*User{
+			{ID: "u1", AccessToken: "access-u1", RefreshToken: "refresh-u1", Code: "code-u1", VehicleIDs: []string{"v1", "v2"}},
+		},
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Now:          func() time.Time { return s.now },
+	})
+	s.client = s.server.NewClient()
+}
+
+func (s *ServerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ServerTestSuite) TestRead() {
+	v := s.server.NewVehicle("v1")
+
+	info, err := v.GetInfo(context.Background())
+	odometer, odometerErr := v.GetOdometer(context.Background(), smartcar.WithUnits(smartcar.Imperial))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "TESLA", info.Make)
+	assert.Equal(s.T(), 2019, info.Year)
+	assert.NotEmpty(s.T(), info.RequestID)
+	assert.Equal(s.T(), s.now, info.DataAge)
+	assert.Nil(s.T(), odometerErr)
+	assert.Equal(s.T(), smartcar.Imperial, odometer.Distance.Units)
+	assert.InDelta(s.T(), 1000, odometer.Distance.Kilometers(), 1e-9)
+}
+
+func (s *ServerTestSuite) TestBatch() {
+	s.server.Fail(&FailParams{VehicleID: "v1", Path: smartcar.LocationPath, Error: &smartcar.Error{
+		StatusCode: http.StatusConflict, Type: "VEHICLE_STATE", Code: "ASLEEP",
+	}})
+
+	data, err := s.server.NewVehicle("v1").Batch(context.Background(), smartcar.BatteryPath, smartcar.VINPath, smartcar.LocationPath)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 0.5, data.Battery.PercentRemaining)
+	assert.Equal(s.T(), 200.0, data.Battery.Range.Value)
+	assert.Equal(s.T(), smartcar.Metric, data.Battery.Range.Units)
+	assert.Equal(s.T(), "5YJ3E1EA1JF000001", data.VIN.VIN)
+	assert.Nil(s.T(), data.Location)
+	assert.Equal(s.T(), "ASLEEP", data.Errors[smartcar.LocationPath].Code)
+}
+
+func (s *ServerTestSuite) TestLockUnlock() {
+	v := s.server.NewVehicle("v1")
+
+	_, err := v.Lock(context.Background())
+	locked := s.server.Vehicle("v1").Locked
+	_, unlockErr := v.Unlock(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), locked)
+	assert.Nil(s.T(), unlockErr)
+	assert.False(s.T(), s.server.Vehicle("v1").Locked)
+}
+
+func (s *ServerTestSuite) TestCharge() {
+	v := s.server.NewVehicle("v2")
+
+	_, err := v.StartCharge(context.Background())
+	s.now = s.now.Add(time.Hour)
+	battery, batteryErr := v.GetBattery(context.Background())
+	charge, chargeErr := v.GetCharge(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.Nil(s.T(), batteryErr)
+	assert.InDelta(s.T(), 0.2+defaultChargePower/defaultCapacity, battery.PercentRemaining, 1e-9)
+	assert.Nil(s.T(), chargeErr)
+	assert.Equal(s.T(), "CHARGING", charge.State)
+
+	// The battery stops charging at the charge limit.
+	s.now = s.now.Add(24 * time.Hour)
+	battery, _ = v.GetBattery(context.Background())
+	charge, _ = v.GetCharge(context.Background())
+	assert.Equal(s.T(), 1.0, battery.PercentRemaining)
+	assert.Equal(s.T(), "FULLY_CHARGED", charge.State)
+	assert.InDelta(s.T(), 0.8*defaultCapacity, s.server.Vehicle("v2").EnergyAdded, 1e-9)
+}
+
+func (s *ServerTestSuite) TestChargeNotPluggedIn() {
+	_, err := s.server.NewVehicle("v1").StartCharge(context.Background())
+
+	assert.Equal(s.T(), "CHARGING_PLUG_NOT_CONNECTED", err.(*smartcar.Error).Code)
+	assert.False(s.T(), s.server.Vehicle("v1").Charging)
+}
+
+func (s *ServerTestSuite) TestUpdateVehicle() {
+	ok := s.server.UpdateVehicle("v1", func(v *Vehicle) {
+		v.Latitude, v.Longitude = 40.7, -74
+	})
+	location, err := s.server.NewVehicle("v1").GetLocation(context.Background())
+
+	assert.True(s.T(), ok)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 40.7, location.Latitude)
+	assert.False(s.T(), s.server.UpdateVehicle("unknown", func(*Vehicle) {}))
+}
+
+func (s *ServerTestSuite) TestFail() {
+	s.server.Fail(&FailParams{Path: smartcar.OdometerPath, Times: 1, Error: &smartcar.Error{
+		StatusCode: http.StatusTooManyRequests, Type: "RATE_LIMIT", RetryAfter: 2 * time.Second,
+	}})
+	v := s.server.NewVehicle("v1")
+
+	_, err := v.GetOdometer(context.Background())
+	_, retryErr := v.GetOdometer(context.Background())
+
+	assert.Equal(s.T(), http.StatusTooManyRequests, err.(*smartcar.Error).StatusCode)
+	assert.Equal(s.T(), "RATE_LIMIT", err.(*smartcar.Error).Type)
+	assert.Equal(s.T(), 2*time.Second, err.(*smartcar.Error).RetryAfter)
+	assert.Nil(s.T(), retryErr)
+}
+
+func (s *ServerTestSuite) TestClearFailures() {
+	s.server.Fail(&FailParams{VehicleID: "v1", Error: &smartcar.Error{StatusCode: http.StatusInternalServerError}})
+	v := s.server.NewVehicle("v1")
+
+	_, err := v.GetVIN(context.Background())
+	_, otherErr := s.server.NewVehicle("v2").GetVIN(context.Background())
+	s.server.ClearFailures()
+	_, clearedErr := v.GetVIN(context.Background())
+
+	assert.Equal(s.T(), http.StatusInternalServerError, err.(*smartcar.Error).StatusCode)
+	assert.Nil(s.T(), otherErr)
+	assert.Nil(s.T(), clearedErr)
+}
+
+func (s *ServerTestSuite) TestUnauthorized() {
+	v := s.client.NewVehicle(&smartcar.VehicleParams{ID: "v1", AccessToken: "access-v3"})
+
+	_, err := v.GetVIN(context.Background())
+	_, tokenErr := s.client.NewVehicle(&smartcar.VehicleParams{ID: "v1", AccessToken: "invalid"}).GetVIN(context.Background())
+
+	assert.Equal(s.T(), http.StatusNotFound, err.(*smartcar.Error).StatusCode)
+	assert.Equal(s.T(), http.StatusUnauthorized, tokenErr.(*smartcar.Error).StatusCode)
+}
+
+func (s *ServerTestSuite) TestUser() {
+	userID, err := s.client.GetUserID(context.Background(), &smartcar.UserIDParams{Access: "access-u1"})
+	vehicleIDs, vehiclesErr := s.client.GetVehicleIDs(context.Background(), &smartcar.VehicleIDsParams{Access: "access-u1"})
+	otherIDs, _ := s.client.GetVehicleIDs(context.Background(), &smartcar.VehicleIDsParams{Access: "access-v3"})
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "u1", *userID)
+	assert.Nil(s.T(), vehiclesErr)
+	assert.Equal(s.T(), []string{"v1", "v2"}, *vehicleIDs)
+	assert.Equal(s.T(), []string{"v3"}, *otherIDs)
+}
+
+func (s *ServerTestSuite) TestDisconnect() {
+	v := s.server.NewVehicle("v1")
+
+	_, err := v.Disconnect(context.Background())
+	_, infoErr := v.GetInfo(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"v2"}, s.server.User("u1").VehicleIDs)
+	assert.Equal(s.T(), http.StatusNotFound, infoErr.(*smartcar.Error).StatusCode)
+}
+
+func (s *ServerTestSuite) TestCompatibility() {
+	params := &smartcar.VINCompatibleParams{VIN: "5YJ3E1EA1JF000001", ClientID: "client-id", ClientSecret: "client-secret"}
+
+	compatible, err := s.client.IsVINCompatible(context.Background(), params)
+	params.VIN = "00000000000000000"
+	incompatible, _ := s.client.IsVINCompatible(context.Background(), params)
+	params.ClientSecret = "invalid"
+	_, authErr := s.client.IsVINCompatible(context.Background(), params)
+
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), compatible)
+	assert.False(s.T(), incompatible)
+	assert.Equal(s.T(), http.StatusUnauthorized, authErr.(*smartcar.Error).StatusCode)
+}
+
+func (s *ServerTestSuite) TestToken() {
+	auth := s.client.NewAuth(&smartcar.AuthParams{ClientID: "client-id", ClientSecret: "client-secret"})
+
+	token, err := auth.ExchangeCode(context.Background(), &smartcar.ExchangeCodeParams{Code: "code-u1"})
+	_, reusedErr := auth.ExchangeCode(context.Background(), &smartcar.ExchangeCodeParams{Code: "code-u1"})
+	refreshed, refreshErr := auth.ExchangeRefreshToken(context.Background(), &smartcar.ExchangeRefreshTokenParams{Token: token.Refresh})
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "access-u1", token.Access)
+	assert.Equal(s.T(), "invalid_grant", reusedErr.(*smartcar.Error).Type)
+	assert.Nil(s.T(), refreshErr)
+	assert.NotEqual(s.T(), "access-u1", refreshed.Access)
+	assert.Equal(s.T(), refreshed.Access, s.server.User("u1").AccessToken)
+
+	// The previous tokens are no longer valid.
+	_, oldErr := s.client.GetUserID(context.Background(), &smartcar.UserIDParams{Access: "access-u1"})
+	assert.Equal(s.T(), http.StatusUnauthorized, oldErr.(*smartcar.Error).StatusCode)
+}
+
+func (s *ServerTestSuite) TestRateLimitedClient() {
+	s.server.Fail(&FailParams{VehicleID: "v1", Times: 1, Error: &smartcar.Error{
+		StatusCode: http.StatusTooManyRequests, Type: "RATE_LIMIT", RetryAfter: time.Second,
+	}})
+	client := s.server.NewClient(smartcar.WithRateLimit(&smartcar.RateLimitParams{MaxRetries: 1}))
+	v := client.NewVehicle(&smartcar.VehicleParams{ID: "v1", AccessToken: "access-u1"})
+
+	vin, err := v.GetVIN(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "5YJ3E1EA1JF000001", vin.VIN)
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
@@ -0,0 +1,177 @@
+package smartcartest
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// simulationStep is the longest period simulated at once, shorter steps follow the charging curve more closely.
+const simulationStep = time.Minute
+
+// Charging slows down linearly from taperStart to the full battery, where it charges at taperEnd of the power.
+const (
+	taperStart = 0.8
+	taperEnd   = 0.1
+)
+
+// defaultTireLeak is how much pressure tires lose per day, in kilopascals.
+const defaultTireLeak = 0.3
+
+// Clock is a clock that only moves when it is told to. Pass clock.Now as ServerParams.Now to control the
+// simulation of a Server.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a Clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d and returns its new time.
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set moves the clock to now.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Waypoint is a point of the route of a Vehicle.
+type Waypoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// simulate evolves the state of the vehicle over d.
+func (v *Vehicle) simulate(d time.Duration) {
+	for d > 0 {
+		step := d
+		if step > simulationStep {
+			step = simulationStep
+		}
+		d -= step
+		v.leak(step)
+		v.drive(step)
+		v.charge(step)
+	}
+}
+
+// leak lowers the pressure of the tires over d.
+func (v *Vehicle) leak(d time.Duration) {
+	if v.NoTireLeak {
+		return
+	}
+	days := d.Hours() / 24
+	for i := range v.TirePressure {
+		v.TirePressure[i] = math.Max(0, v.TirePressure[i]-v.TireLeak[i]*days)
+	}
+}
+
+// drive moves the vehicle along its route over d, advancing the odometer and using its battery, or its tank when it
+// has one. The vehicle stops when it reaches the end of its route or runs out of energy.
+func (v *Vehicle) drive(d time.Duration) {
+	if len(v.Route) == 0 || v.Speed <= 0 || v.PluggedIn || v.energy() <= 0 {
+		v.park()
+		return
+	}
+	v.driving, v.Ignition = true, smartcar.IgnitionOn
+
+	// The vehicle drives at most the distance its energy allows.
+	km := math.Min(v.Speed*d.Hours(), v.energy()*v.MaxRange)
+	driven := 0.0
+	for km-driven > 0 && len(v.Route) > 0 {
+		here := &smartcar.Location{Latitude: v.Latitude, Longitude: v.Longitude}
+		next := &smartcar.Location{Latitude: v.Route[0].Latitude, Longitude: v.Route[0].Longitude}
+		segment := here.DistanceTo(next) / 1000
+		if segment > 0 {
+			v.Heading = bearing(here, next)
+		}
+		if segment <= km-driven {
+			v.Latitude, v.Longitude = next.Latitude, next.Longitude
+			v.Route = v.Route[1:]
+			driven += segment
+			continue
+		}
+		f := (km - driven) / segment
+		v.Latitude += (next.Latitude - v.Latitude) * f
+		v.Longitude += (next.Longitude - v.Longitude) * f
+		driven = km
+	}
+
+	v.Odometer += driven
+	if v.FuelCapacity > 0 {
+		v.FuelPercent = math.Max(0, v.FuelPercent-driven/v.MaxRange)
+	} else {
+		v.PercentRemaining = math.Max(0, v.PercentRemaining-driven/v.MaxRange)
+	}
+	if len(v.Route) == 0 || v.energy() <= 0 {
+		v.park()
+	}
+}
+
+// park stops the vehicle, if it was driving.
+func (v *Vehicle) park() {
+	if v.driving {
+		v.driving, v.Ignition = false, smartcar.IgnitionOff
+	}
+}
+
+// energy returns what is left in the tank of the vehicle, or in its battery when it has no tank, between 0 and 1.
+func (v *Vehicle) energy() float64 {
+	if v.FuelCapacity > 0 {
+		return v.FuelPercent
+	}
+	return v.PercentRemaining
+}
+
+// chargePower returns the power the battery charges at, which tapers off as the battery gets full.
+func (v *Vehicle) chargePower() float64 {
+	if v.PercentRemaining <= taperStart {
+		return v.ChargePower
+	}
+	f := math.Min(1, (v.PercentRemaining-taperStart)/(1-taperStart))
+	return v.ChargePower * (1 - (1-taperEnd)*f)
+}
+
+// charge adds the energy the vehicle gained while charging for d.
+func (v *Vehicle) charge(d time.Duration) {
+	if !v.Charging || !v.PluggedIn {
+		v.Charging = false
+		return
+	}
+	percent := math.Min(v.chargePower()*d.Hours()/v.Capacity, v.ChargeLimit-v.PercentRemaining)
+	if percent > 0 {
+		v.PercentRemaining += percent
+		v.EnergyAdded += percent * v.Capacity
+	}
+	if v.PercentRemaining >= v.ChargeLimit {
+		v.Charging = false
+	}
+}
+
+// bearing returns the initial bearing from a to b, in degrees clockwise from true north.
+func bearing(a, b *smartcar.Location) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
@@ -0,0 +1,199 @@
+package smartcartest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/smartcar/go-sdk/charging"
+	"github.com/smartcar/go-sdk/trip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// kmPerDegree is the length of a degree of latitude, or of longitude on the equator, in kilometers.
+const kmPerDegree = 111.195
+
+type SimulatorTestSuite struct {
+	suite.Suite
+	clock  *Clock
+	server *Server
+}
+
+func (s *SimulatorTestSuite) SetupTest() {
+	s.clock = NewClock(time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC))
+	s.server = NewServer(&ServerParams{
+		Vehicles: []*Vehicle{
+			{ID: "ev", PercentRemaining: 0.9, Odometer: 1000},
+			{ID: "gas", FuelCapacity: 50, FuelPercent: 1, MaxRange: 600},
+		},
+		Now: s.clock.Now,
+	})
+}
+
+func (s *SimulatorTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+// drive sends a vehicle along a route that goes 1 degree east then 1 degree north of the equator.
+func (s *SimulatorTestSuite) drive(vehicleID string, speed float64) {
+	s.server.UpdateVehicle(vehicleID, func(v *Vehicle) {
+		v.Route = []Waypoint{{Latitude: 0, Longitude: 1}, {Latitude: 1, Longitude: 1}}
+		v.Speed = speed
+	})
+}
+
+func (s *SimulatorTestSuite) TestClock() {
+	start := s.clock.Now()
+
+	advanced := s.clock.Advance(time.Hour)
+	s.clock.Set(start)
+
+	assert.Equal(s.T(), start.Add(time.Hour), advanced)
+	assert.Equal(s.T(), start, s.clock.Now())
+}
+
+func (s *SimulatorTestSuite) TestDrive() {
+	s.drive("ev", 60)
+	v := s.server.NewVehicle("ev")
+
+	s.clock.Advance(30 * time.Minute)
+	location, err := v.GetLocation(context.Background())
+	ignition, _ := v.GetIgnition(context.Background())
+	state := s.server.Vehicle("ev")
+
+	assert.Nil(s.T(), err)
+	assert.InDelta(s.T(), 30/kmPerDegree, location.Longitude, 1e-3)
+	assert.Equal(s.T(), 0.0, location.Latitude)
+	assert.Equal(s.T(), 60.0, location.Speed.Value)
+	assert.InDelta(s.T(), 90, *location.Heading, 1e-6)
+	assert.Equal(s.T(), smartcar.IgnitionOn, ignition.State)
+	assert.InDelta(s.T(), 1030, state.Odometer, 1e-6)
+	assert.InDelta(s.T(), 0.9-30.0/defaultMaxRange, state.PercentRemaining, 1e-9)
+
+	// The vehicle parks at the end of its route.
+	s.clock.Advance(4 * time.Hour)
+	location, _ = v.GetLocation(context.Background())
+	state = s.server.Vehicle("ev")
+	assert.Equal(s.T(), 1.0, location.Latitude)
+	assert.Equal(s.T(), 1.0, location.Longitude)
+	assert.Nil(s.T(), location.Speed)
+	assert.InDelta(s.T(), 1000+2*kmPerDegree, state.Odometer, 0.1)
+	assert.Equal(s.T(), smartcar.IgnitionOff, state.Ignition)
+	assert.Empty(s.T(), state.Route)
+}
+
+func (s *SimulatorTestSuite) TestDriveFuel() {
+	s.drive("gas", 60)
+
+	s.clock.Advance(time.Hour)
+	fuel, err := s.server.NewVehicle("gas").GetFuel(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.InDelta(s.T(), 0.9, fuel.PercentRemaining, 1e-9)
+	assert.InDelta(s.T(), 45, fuel.AmountRemaining.Value, 1e-6)
+	assert.Equal(s.T(), 0.0, s.server.Vehicle("gas").PercentRemaining)
+}
+
+func (s *SimulatorTestSuite) TestOutOfEnergy() {
+	s.server.UpdateVehicle("ev", func(v *Vehicle) { v.PercentRemaining = 0.02 })
+	s.drive("ev", 60)
+
+	s.clock.Advance(time.Hour)
+	state := s.server.Vehicle("ev")
+
+	assert.InDelta(s.T(), 1008, state.Odometer, 1e-6)
+	assert.Equal(s.T(), 0.0, state.PercentRemaining)
+	assert.Equal(s.T(), smartcar.IgnitionOff, state.Ignition)
+	assert.Len(s.T(), state.Route, 2)
+}
+
+func (s *SimulatorTestSuite) TestPluggedInDoesNotDrive() {
+	s.server.UpdateVehicle("ev", func(v *Vehicle) { v.PluggedIn = true })
+	s.drive("ev", 60)
+
+	s.clock.Advance(time.Hour)
+
+	assert.Equal(s.T(), 1000.0, s.server.Vehicle("ev").Odometer)
+}
+
+func (s *SimulatorTestSuite) TestChargingCurve() {
+	s.server.UpdateVehicle("ev", func(v *Vehicle) {
+		v.PercentRemaining, v.PluggedIn, v.Charging = 0.8, true, true
+	})
+	v := s.server.NewVehicle("ev")
+
+	rate, _ := v.GetChargeRate(context.Background())
+	completion, _ := v.GetChargeCompletion(context.Background())
+	s.clock.Advance(time.Hour)
+	battery, err := v.GetBattery(context.Background())
+	taperedRate, _ := v.GetChargeRate(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), defaultChargePower, rate.Power)
+	assert.True(s.T(), battery.PercentRemaining > 0.8)
+	assert.True(s.T(), battery.PercentRemaining < 0.8+defaultChargePower/defaultCapacity)
+	assert.True(s.T(), taperedRate.Power < rate.Power)
+	// Charging the last 20% at full power would take 125 minutes.
+	assert.True(s.T(), completion.Duration() > 125*time.Minute)
+}
+
+func (s *SimulatorTestSuite) TestTireLeak() {
+	s.clock.Advance(10 * 24 * time.Hour)
+
+	pressure, err := s.server.NewVehicle("ev").GetTiresPressure(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.InDelta(s.T(), defaultTirePressure-10*defaultTireLeak, pressure.FrontLeft.Value, 1e-6)
+	assert.InDelta(s.T(), defaultTirePressure-10*defaultTireLeak, pressure.BackRight.Value, 1e-6)
+}
+
+func (s *SimulatorTestSuite) TestNoTireLeak() {
+	s.server.UpdateVehicle("ev", func(v *Vehicle) { v.NoTireLeak = true })
+	s.clock.Advance(10 * 24 * time.Hour)
+
+	pressure, err := s.server.NewVehicle("ev").GetTiresPressure(context.Background())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), float64(defaultTirePressure), pressure.FrontLeft.Value)
+	assert.Equal(s.T(), float64(defaultTirePressure), pressure.BackRight.Value)
+}
+
+func (s *SimulatorTestSuite) TestTimeSeries() {
+	v := s.server.NewVehicle("ev")
+	detector := trip.NewDetector(&trip.DetectorParams{})
+	tracker := charging.NewTracker(&charging.TrackerParams{})
+	var trips []*trip.Trip
+	var events []*charging.Event
+
+	// Drive for 3h42m, then fast charge, polling every 5 minutes for 6 hours.
+	s.server.UpdateVehicle("ev", func(v *Vehicle) { v.ChargePower = 100 })
+	s.drive("ev", 60)
+	for i := 0; i < 72; i++ {
+		if i == 48 {
+			s.server.UpdateVehicle("ev", func(v *Vehicle) { v.PluggedIn = true })
+			v.StartCharge(context.Background())
+		}
+		data, err := v.Batch(context.Background(), smartcar.OdometerPath, smartcar.LocationPath, smartcar.ChargePath, smartcar.BatteryPath)
+		assert.Nil(s.T(), err)
+		if t := detector.Add(trip.NewReading(data.Odometer, data.Location)); t != nil {
+			trips = append(trips, t)
+		}
+		if event := tracker.Add("ev", charging.NewReading(data)); event != nil {
+			events = append(events, event)
+		}
+		s.clock.Advance(5 * time.Minute)
+	}
+
+	assert.Len(s.T(), trips, 1)
+	assert.InDelta(s.T(), 2*kmPerDegree, trips[0].Distance.Value, 0.5)
+	assert.Len(s.T(), events, 2)
+	assert.Equal(s.T(), charging.Started, events[0].Type)
+	assert.Equal(s.T(), charging.Ended, events[1].Type)
+	assert.InDelta(s.T(), 1, events[1].Session.EndPercent, 1e-9)
+}
+
+func TestSimulatorTestSuite(t *testing.T) {
+	suite.Run(t, new(SimulatorTestSuite))
+}
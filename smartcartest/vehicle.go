@@ -0,0 +1,217 @@
+package smartcartest
+
+import (
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// Defaults of the zero fields of a Vehicle.
+const (
+	defaultCapacity      = 75
+	defaultMaxRange      = 400
+	defaultChargeLimit   = 1
+	defaultChargePower   = 7.2
+	defaultChargeVoltage = 240
+	defaultTirePressure  = 240
+)
+
+// maxTimeToComplete bounds the charging time reported by vehicles that charge very slowly.
+const maxTimeToComplete = 7 * 24 * time.Hour
+
+// defaultPermissions are the permissions of a Vehicle without Permissions.
+var defaultPermissions = []string{
+	"read_battery",
+	"read_charge",
+	"read_fuel",
+	"read_location",
+	"read_odometer",
+	"read_engine_oil",
+	"read_tires",
+	"read_vehicle_info",
+	"read_vin",
+	"control_charge",
+	"control_security",
+}
+
+// Vehicle is the state of a simulated vehicle. Distances are in kilometers, speeds in kilometers per hour, volumes
+// in liters and pressures in kilopascals, responses are converted to the unit system of the request. Zero fields are
+// set to sensible defaults by NewServer.
+//
+// The state evolves as the time given by ServerParams.Now passes: the vehicle drives along its Route, tires lose
+// pressure and the battery charges.
+type Vehicle struct {
+	ID          string
+	VIN         string
+	Make        string
+	Model       string
+	Year        int
+	Permissions []string
+	Ignition    string
+
+	Locked    bool
+	Odometer  float64
+	Latitude  float64
+	Longitude float64
+	OilLife   float64
+	// TirePressure is the pressure of the front left, front right, back left and back right tires, and TireLeak
+	// how much pressure they lose per day. A zero TireLeak loses the default pressure, unless NoTireLeak is set.
+	TirePressure [4]float64
+	TireLeak     [4]float64
+	NoTireLeak   bool
+
+	// The vehicle drives at Speed to the waypoints of Route, which are removed as they are reached, unless it is
+	// plugged in. Every kilometer uses 1/MaxRange of its tank, or of its battery when it has no FuelCapacity.
+	// Heading is the direction it last drove in.
+	Route   []Waypoint
+	Speed   float64
+	Heading float64
+	driving bool
+
+	// PercentRemaining is the state of charge of the battery, between 0 and 1. MaxRange is the range of the vehicle
+	// with a full battery or tank, and Capacity the capacity of the battery in kWh.
+	PercentRemaining float64
+	MaxRange         float64
+	Capacity         float64
+
+	// While Charging, the battery gains ChargePower kW until it reaches ChargeLimit, between 0 and 1.
+	PluggedIn     bool
+	Charging      bool
+	ChargeLimit   float64
+	ChargePower   float64
+	ChargeVoltage float64
+	ChargerType   string
+	// EnergyAdded is the energy added since charging last started, in kWh.
+	EnergyAdded float64
+
+	FuelPercent  float64
+	FuelCapacity float64
+}
+
+// setDefaults sets the zero fields of v to their defaults.
+func (v *Vehicle) setDefaults() {
+	if v.Permissions == nil {
+		v.Permissions = defaultPermissions
+	}
+	if v.Ignition == "" {
+		v.Ignition = smartcar.IgnitionOff
+	}
+	if v.TirePressure == [4]float64{} {
+		v.TirePressure = [4]float64{defaultTirePressure, defaultTirePressure, defaultTirePressure, defaultTirePressure}
+	}
+	if v.TireLeak == [4]float64{} && !v.NoTireLeak {
+		v.TireLeak = [4]float64{defaultTireLeak, defaultTireLeak, defaultTireLeak, defaultTireLeak}
+	}
+	if v.MaxRange == 0 {
+		v.MaxRange = defaultMaxRange
+	}
+	if v.Capacity == 0 {
+		v.Capacity = defaultCapacity
+	}
+	if v.ChargeLimit == 0 {
+		v.ChargeLimit = defaultChargeLimit
+	}
+	if v.ChargePower == 0 {
+		v.ChargePower = defaultChargePower
+	}
+	if v.ChargeVoltage == 0 {
+		v.ChargeVoltage = defaultChargeVoltage
+	}
+	if v.ChargerType == "" {
+		v.ChargerType = smartcar.ChargerTypeAC
+	}
+}
+
+// timeToComplete returns how long the vehicle charges until it reaches its charge limit, following the charging
+// curve. It is 0 when the vehicle is not charging.
+func (v *Vehicle) timeToComplete() time.Duration {
+	c := *v
+	var d time.Duration
+	for c.Charging && c.PluggedIn && d < maxTimeToComplete {
+		c.charge(simulationStep)
+		d += simulationStep
+	}
+	return d
+}
+
+// chargeState is the Charge.State of the vehicle.
+func (v *Vehicle) chargeState() string {
+	switch {
+	case v.Charging:
+		return "CHARGING"
+	case v.PluggedIn && v.PercentRemaining >= v.ChargeLimit:
+		return "FULLY_CHARGED"
+	}
+	return "NOT_CHARGING"
+}
+
+// read returns the response of a vehicle endpoint, or nil when the path is not a vehicle endpoint.
+func (v *Vehicle) read(path smartcar.Key, units smartcar.UnitSystem) interface{} {
+	distance := func(km float64) smartcar.Distance {
+		return smartcar.Distance{Value: km, Units: smartcar.Metric}.In(units)
+	}
+	pressure := func(kpa float64) smartcar.Pressure {
+		return smartcar.Pressure{Value: kpa, Units: smartcar.Metric}.In(units)
+	}
+
+	switch path {
+	case smartcar.BatteryPath:
+		return &smartcar.Battery{PercentRemaining: v.PercentRemaining, Range: distance(v.PercentRemaining * v.MaxRange)}
+	case smartcar.BatteryCapacityPath:
+		return &smartcar.BatteryCapacity{Capacity: v.Capacity}
+	case smartcar.ChargePath:
+		return &smartcar.Charge{IsPluggedIn: v.PluggedIn, State: v.chargeState()}
+	case smartcar.ChargeAmperagePath:
+		amperage := 0.0
+		if v.Charging {
+			amperage = v.chargePower() * 1000 / v.ChargeVoltage
+		}
+		return &smartcar.ChargeAmperage{Amperage: amperage}
+	case smartcar.ChargeCompletionPath:
+		return &smartcar.ChargeCompletion{TimeToComplete: v.timeToComplete().Minutes()}
+	case smartcar.ChargeEnergyAddedPath:
+		return &smartcar.ChargeEnergyAdded{EnergyAdded: v.EnergyAdded}
+	case smartcar.ChargeRatePath:
+		power := 0.0
+		if v.Charging {
+			power = v.chargePower()
+		}
+		rangeRate := smartcar.Speed{Value: power / v.Capacity * v.MaxRange, Units: smartcar.Metric}.In(units)
+		return &smartcar.ChargeRate{Power: power, RangeRate: rangeRate}
+	case smartcar.ChargeVoltagePath:
+		return &smartcar.ChargeVoltage{Voltage: v.ChargeVoltage}
+	case smartcar.ChargerTypePath:
+		return &smartcar.ChargerType{Type: v.ChargerType}
+	case smartcar.FuelPath:
+		amount := smartcar.Volume{Value: v.FuelPercent * v.FuelCapacity, Units: smartcar.Metric}.In(units)
+		return &smartcar.Fuel{AmountRemaining: amount, PercentRemaining: v.FuelPercent, Range: distance(v.FuelPercent * v.MaxRange)}
+	case smartcar.IgnitionPath:
+		return &smartcar.Ignition{State: v.Ignition, IsEngineRunning: v.Ignition == smartcar.IgnitionOn}
+	case smartcar.InfoPath:
+		return &smartcar.Info{ID: v.ID, Make: v.Make, Model: v.Model, Year: v.Year}
+	case smartcar.LocationPath:
+		location := &smartcar.Location{Latitude: v.Latitude, Longitude: v.Longitude}
+		if v.driving {
+			heading := v.Heading
+			speed := smartcar.Speed{Value: v.Speed, Units: smartcar.Metric}.In(units)
+			location.Heading, location.Speed = &heading, &speed
+		}
+		return location
+	case smartcar.OdometerPath:
+		return &smartcar.Odometer{Distance: distance(v.Odometer)}
+	case smartcar.OilPath:
+		return &smartcar.Oil{LifeRemaining: v.OilLife}
+	case smartcar.PermissionsPath:
+		return &smartcar.Permissions{Permissions: v.Permissions}
+	case smartcar.TirePressurePath:
+		return &smartcar.TirePressure{
+			FrontLeft:  pressure(v.TirePressure[0]),
+			FrontRight: pressure(v.TirePressure[1]),
+			BackLeft:   pressure(v.TirePressure[2]),
+			BackRight:  pressure(v.TirePressure[3]),
+		}
+	case smartcar.VINPath:
+		return &smartcar.VIN{VIN: v.VIN}
+	}
+	return nil
+}
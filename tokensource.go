@@ -0,0 +1,75 @@
+package smartcar
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenSource provides the access token of a vehicle.
+type TokenSource interface {
+	Token(context.Context) (string, error)
+}
+
+// TokenSourceFunc is a function that implements TokenSource.
+type TokenSourceFunc func(context.Context) (string, error)
+
+// Token implements TokenSource.
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource that always returns accessToken.
+func StaticTokenSource(accessToken string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		return accessToken, nil
+	})
+}
+
+// RefreshTokenSourceParams is a param in smartcar.NewRefreshTokenSource
+type RefreshTokenSourceParams struct {
+	Auth  Auth
+	Token *Token
+	// OnRefresh is called with every new token, refresh tokens can only be used once so they need to be stored.
+	OnRefresh func(*Token)
+}
+
+// refreshTokenSource implements the TokenSource interface.
+type refreshTokenSource struct {
+	auth      Auth
+	onRefresh func(*Token)
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewRefreshTokenSource returns a TokenSource that exchanges the refresh token of params.Token for a new token
+// when its access token is expired. It is safe for concurrent use.
+func NewRefreshTokenSource(params *RefreshTokenSourceParams) TokenSource {
+	return &refreshTokenSource{
+		auth:      params.Auth,
+		onRefresh: params.OnRefresh,
+		token:     params.Token,
+	}
+}
+
+// Token implements TokenSource.
+func (s *refreshTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Refresh a little early, so the token does not expire while the request is sent.
+	if time.Now().Before(s.token.AccessExpiry.Add(-10 * time.Second)) {
+		return s.token.Access, nil
+	}
+
+	token, err := s.auth.ExchangeRefreshToken(ctx, &ExchangeRefreshTokenParams{Token: s.token.Refresh})
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	if s.onRefresh != nil {
+		s.onRefresh(token)
+	}
+	return token.Access, nil
+}
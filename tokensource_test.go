@@ -0,0 +1,76 @@
+package smartcar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRefreshAuth exchanges refresh tokens for tokens that expire in an hour.
+type fakeRefreshAuth struct {
+	Auth
+	refreshed []string
+	err       error
+}
+
+func (a *fakeRefreshAuth) ExchangeRefreshToken(ctx context.Context, params *ExchangeRefreshTokenParams) (*Token, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	a.refreshed = append(a.refreshed, params.Token)
+	return &Token{
+		Access:       "access-2",
+		AccessExpiry: time.Now().Add(time.Hour),
+		Refresh:      "refresh-2",
+	}, nil
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	// Act
+	token, err := StaticTokenSource("access").Token(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "access", token)
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	// Arrange
+	auth := &fakeRefreshAuth{}
+	var stored *Token
+	source := NewRefreshTokenSource(&RefreshTokenSourceParams{
+		Auth:      auth,
+		Token:     &Token{Access: "access-1", AccessExpiry: time.Now().Add(-time.Minute), Refresh: "refresh-1"},
+		OnRefresh: func(token *Token) { stored = token },
+	})
+
+	// Act
+	first, err := source.Token(context.Background())
+	second, _ := source.Token(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "access-2", first)
+	assert.Equal(t, "access-2", second)
+	assert.Equal(t, []string{"refresh-1"}, auth.refreshed)
+	assert.Equal(t, "refresh-2", stored.Refresh)
+}
+
+func TestRefreshTokenSourceError(t *testing.T) {
+	// Arrange
+	failure := errors.New("invalid_grant")
+	source := NewRefreshTokenSource(&RefreshTokenSourceParams{
+		Auth:  &fakeRefreshAuth{err: failure},
+		Token: &Token{Access: "access-1", Refresh: "refresh-1"},
+	})
+
+	// Act
+	token, err := source.Token(context.Background())
+
+	// Assert
+	assert.Equal(t, failure, err)
+	assert.Equal(t, "", token)
+}
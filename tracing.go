@@ -0,0 +1,164 @@
+package smartcar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Attributes of the spans of WithTracer, which follow the semantic conventions of OpenTelemetry for HTTP clients.
+const (
+	AttributeHTTPMethod     = "http.request.method"
+	AttributeHTTPStatusCode = "http.response.status_code"
+	AttributeURL            = "url.full"
+	AttributeServerAddress  = "server.address"
+	AttributeErrorType      = "error.type"
+	AttributeAPIVersion     = "smartcar.api_version"
+	AttributeVehicleID      = "smartcar.vehicle_id"
+	AttributePath           = "smartcar.path"
+	AttributeRequestID      = "smartcar.request_id"
+	AttributeAttempt        = "smartcar.attempt"
+)
+
+// Attribute is an attribute of a Span. Values are strings, ints or bools.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts the spans of the requests of a client, i.e. with the otelsmartcar adapter for OpenTelemetry.
+type Tracer interface {
+	// Start starts a span that is a child of the span of ctx, and returns a context holding the new span.
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	// End ends the span, err is the error of a failed request.
+	End(err error)
+}
+
+// WithTracer traces every request of the client, and every path of the requests of vehicle.Batch. Spans are
+// children of the span in the context of the request, and the context of the span is given to the http.Client.
+// Like other middlewares, the tracer only sees the requests that reach it, after the middlewares added before it.
+func WithTracer(tracer Tracer) ClientOption {
+	return WithMiddleware(newTracing(tracer))
+}
+
+// newTracing returns a Middleware tracing requests with tracer.
+func newTracing(tracer Tracer) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(r *Request) (*Response, error) {
+			ctx := r.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx, span := tracer.Start(ctx, spanName(r), requestAttributes(r)...)
+			r.Context = ctx
+
+			var pathSpans map[string]Span
+			if r.Path == batchPath {
+				pathSpans = startPathSpans(ctx, tracer, r)
+			}
+
+			res, err := next.RoundTrip(r)
+			if res != nil {
+				span.SetAttributes(Attribute{AttributeHTTPStatusCode, res.StatusCode})
+				if requestID := res.Header.Get("Sc-Request-Id"); requestID != "" {
+					span.SetAttributes(Attribute{AttributeRequestID, requestID})
+				}
+			}
+			if scErr, ok := err.(*Error); ok && scErr.Type != "" {
+				span.SetAttributes(Attribute{AttributeErrorType, scErr.Type})
+			}
+
+			var responses []batchResponseItem
+			if res != nil {
+				if batch, ok := res.Result.(*batchResponse); ok {
+					responses = batch.Responses
+				}
+			}
+			endPathSpans(pathSpans, responses, err)
+			span.End(err)
+			return res, err
+		})
+	}
+}
+
+// spanName returns the name of the span of r, i.e. "smartcar GET /odometer".
+func spanName(r *Request) string {
+	path := string(r.Path)
+	if path == "" {
+		if u, err := url.Parse(r.URL); err == nil {
+			path = u.Path
+		}
+	}
+	return fmt.Sprintf("smartcar %s %s", r.Method, path)
+}
+
+// requestAttributes returns the attributes of the span of r.
+func requestAttributes(r *Request) []Attribute {
+	attributes := []Attribute{
+		{AttributeHTTPMethod, r.Method},
+		{AttributeURL, redactURL(r.URL)},
+		{AttributeAPIVersion, APIVersion},
+		{AttributeAttempt, r.Attempt},
+	}
+	if u, err := url.Parse(r.URL); err == nil {
+		attributes = append(attributes, Attribute{AttributeServerAddress, u.Hostname()})
+	}
+	if r.VehicleID != "" {
+		attributes = append(attributes, Attribute{AttributeVehicleID, r.VehicleID}, Attribute{AttributePath, string(r.Path)})
+	}
+	return attributes
+}
+
+// startPathSpans starts a span for every path of a batch request.
+func startPathSpans(ctx context.Context, tracer Tracer, r *Request) map[string]Span {
+	request := new(struct {
+		Requests []struct{ Path string } `json:"requests"`
+	})
+	if err := json.Unmarshal(r.Body, request); err != nil {
+		return nil
+	}
+	spans := make(map[string]Span, len(request.Requests))
+	for _, path := range request.Requests {
+		_, spans[path.Path] = tracer.Start(ctx, "smartcar batch "+path.Path,
+			Attribute{AttributeVehicleID, r.VehicleID},
+			Attribute{AttributePath, path.Path},
+		)
+	}
+	return spans
+}
+
+// endPathSpans ends the spans of the paths of a batch request with their responses, or with err when the request
+// failed.
+func endPathSpans(spans map[string]Span, responses []batchResponseItem, err error) {
+	for _, res := range responses {
+		span, ok := spans[res.Path]
+		if !ok || err != nil {
+			continue
+		}
+		delete(spans, res.Path)
+		span.SetAttributes(Attribute{AttributeHTTPStatusCode, res.Code})
+		if res.Headers.RequestID != "" {
+			span.SetAttributes(Attribute{AttributeRequestID, res.Headers.RequestID})
+		}
+		itemErr := batchItemError(res)
+		if itemErr != nil && itemErr.Type != "" {
+			span.SetAttributes(Attribute{AttributeErrorType, itemErr.Type})
+		}
+		// A nil *Error would end the span with a non nil error.
+		if itemErr != nil {
+			span.End(itemErr)
+		} else {
+			span.End(nil)
+		}
+	}
+	// The paths without a response end with the error of the request.
+	for _, span := range spans {
+		span.End(err)
+	}
+}
@@ -0,0 +1,141 @@
+package smartcar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// contextKey is the key of the span of a recordingTracer in a context.
+type contextKey struct{}
+
+// recordingSpan is a Span recorded by a recordingTracer.
+type recordingSpan struct {
+	name       string
+	parent     *recordingSpan
+	attributes map[string]interface{}
+	ended      bool
+	err        error
+}
+
+func (s *recordingSpan) SetAttributes(attributes ...Attribute) {
+	for _, a := range attributes {
+		s.attributes[a.Key] = a.Value
+	}
+}
+
+func (s *recordingSpan) End(err error) {
+	s.ended, s.err = true, err
+}
+
+// recordingTracer is a Tracer recording its spans.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(contextKey{}).(*recordingSpan)
+	span := &recordingSpan{name: name, parent: parent, attributes: map[string]interface{}{}}
+	span.SetAttributes(attributes...)
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, contextKey{}, span), span
+}
+
+// newTracingServer returns a server answering batch requests for the odometer and the location, which fails, and
+// rejecting the other requests because the vehicle is asleep.
+func newTracingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Sc-Request-Id", "request-id")
+		if r.URL.Path != "/v2.0/vehicles/vehicle-id/batch" {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"type":"VEHICLE_STATE","code":"ASLEEP"}`))
+			return
+		}
+		w.Write([]byte(`{"responses":[
+			{"path":"/location","code":409,"headers":{"sc-request-id":"location-id"},"body":{"type":"VEHICLE_STATE","code":"ASLEEP"}},
+			{"path":"/odometer","code":200,"headers":{"sc-request-id":"odometer-id"},"body":{"distance":10}}
+		]}`))
+	}))
+}
+
+func TestTracer(t *testing.T) {
+	// Arrange
+	tracer := &recordingTracer{}
+	server := newTracingServer()
+	defer server.Close()
+	var transportSpan *recordingSpan
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithTracer(tracer),
+		WithMiddleware(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(r *Request) (*Response, error) {
+				transportSpan, _ = r.Context.Value(contextKey{}).(*recordingSpan)
+				return next.RoundTrip(r)
+			})
+		}),
+	)
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+	parentCtx, parent := tracer.Start(context.Background(), "parent")
+
+	// Act
+	_, err := vehicle.Batch(parentCtx, OdometerPath, LocationPath)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, tracer.spans, 4)
+	batch := tracer.spans[1]
+	assert.Equal(t, "smartcar POST /batch", batch.name)
+	assert.Equal(t, parent, batch.parent)
+	assert.Equal(t, batch, transportSpan)
+	assert.True(t, batch.ended)
+	assert.Nil(t, batch.err)
+	assert.Equal(t, http.MethodPost, batch.attributes[AttributeHTTPMethod])
+	assert.Equal(t, server.URL+"/v2.0/vehicles/vehicle-id/batch", batch.attributes[AttributeURL])
+	assert.Equal(t, "127.0.0.1", batch.attributes[AttributeServerAddress])
+	assert.Equal(t, http.StatusOK, batch.attributes[AttributeHTTPStatusCode])
+	assert.Equal(t, "request-id", batch.attributes[AttributeRequestID])
+	assert.Equal(t, "vehicle-id", batch.attributes[AttributeVehicleID])
+	assert.Equal(t, APIVersion, batch.attributes[AttributeAPIVersion])
+	assert.Equal(t, 0, batch.attributes[AttributeAttempt])
+
+	odometer, location := tracer.spans[2], tracer.spans[3]
+	assert.Equal(t, "smartcar batch /odometer", odometer.name)
+	assert.Equal(t, batch, odometer.parent)
+	assert.True(t, odometer.ended)
+	assert.Nil(t, odometer.err)
+	assert.Equal(t, "odometer-id", odometer.attributes[AttributeRequestID])
+	assert.Equal(t, "/location", location.attributes[AttributePath])
+	assert.Equal(t, http.StatusConflict, location.attributes[AttributeHTTPStatusCode])
+	assert.Equal(t, "VEHICLE_STATE", location.attributes[AttributeErrorType])
+	assert.Equal(t, "ASLEEP", location.err.(*Error).Code)
+}
+
+func TestTracerError(t *testing.T) {
+	// Arrange
+	tracer := &recordingTracer{}
+	server := newTracingServer()
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithTracer(tracer))
+	vehicle := client.NewVehicle(&VehicleParams{ID: "vehicle-id", AccessToken: "token"})
+
+	// Act
+	_, err := vehicle.GetOdometer(context.Background())
+
+	// Assert
+	assert.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	assert.Equal(t, "smartcar GET /odometer", span.name)
+	assert.Nil(t, span.parent)
+	assert.Equal(t, err, span.err)
+	assert.Equal(t, http.StatusConflict, span.attributes[AttributeHTTPStatusCode])
+	assert.Equal(t, "VEHICLE_STATE", span.attributes[AttributeErrorType])
+	assert.Equal(t, string(OdometerPath), span.attributes[AttributePath])
+}
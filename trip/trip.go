@@ -0,0 +1,295 @@
+// Package trip segments the odometer and location history of a vehicle into trips.
+package trip
+
+import (
+	"context"
+	"io"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+)
+
+// Defaults used when DetectorParams leaves a field empty.
+const (
+	defaultMinDistance = 100.0
+	defaultStopAfter   = 5 * time.Minute
+	defaultMaxGap      = 30 * time.Minute
+)
+
+// Reading is a point of the history of a vehicle. Either Odometer or Location may be nil.
+type Reading struct {
+	Time     time.Time
+	Odometer *smartcar.Odometer
+	Location *smartcar.Location
+}
+
+// NewReading returns a Reading at the DataAge of odometer, or location when odometer has none.
+func NewReading(odometer *smartcar.Odometer, location *smartcar.Location) *Reading {
+	reading := &Reading{Odometer: odometer, Location: location}
+	if odometer != nil {
+		reading.Time = odometer.DataAge
+	}
+	if reading.Time.IsZero() && location != nil {
+		reading.Time = location.DataAge
+	}
+	return reading
+}
+
+// Source provides the history of a vehicle in chronological order.
+type Source interface {
+	// Next returns the next reading, or io.EOF once the history is exhausted.
+	Next(ctx context.Context) (*Reading, error)
+}
+
+// sliceSource implements the Source interface for readings held in memory.
+type sliceSource struct {
+	readings []*Reading
+}
+
+// NewSliceSource returns a Source that replays readings.
+func NewSliceSource(readings []*Reading) Source {
+	return &sliceSource{readings: readings}
+}
+
+// Next implements Source.
+func (s *sliceSource) Next(ctx context.Context) (*Reading, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.readings) == 0 {
+		return nil, io.EOF
+	}
+	reading := s.readings[0]
+	s.readings = s.readings[1:]
+	return reading, nil
+}
+
+// Trip is a segment of the history during which the vehicle was moving.
+type Trip struct {
+	Start, End                 time.Time
+	StartLocation, EndLocation *smartcar.Location
+	// Distance is the sum of the odometer deltas of the trip, or of the distances between its locations where
+	// the odometer was not read.
+	Distance smartcar.Distance
+	// Sparse is true when some of the distance was traveled between readings further apart than
+	// DetectorParams.MaxGap, so Start, End and Duration are less accurate.
+	Sparse bool
+}
+
+// Duration returns the time between the start and the end of the trip.
+func (t *Trip) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// DetectorParams is a param in trip.NewDetector
+type DetectorParams struct {
+	// MinDistance is how far, in meters, the vehicle needs to move away from where it stood to be considered
+	// moving. Defaults to 100.
+	MinDistance float64
+	// StopAfter is how long the vehicle needs to stay put for the trip to end. Defaults to 5 minutes.
+	StopAfter time.Duration
+	// MaxGap is the longest time between two readings for the distance traveled between them to be considered
+	// accurately placed in time. Defaults to 30 minutes.
+	MaxGap time.Duration
+}
+
+// Detector is a state machine that turns readings into trips. Given the same readings, it always returns
+// the same trips.
+//
+// A trip starts at the last reading where the vehicle stood still, once it moved at least MinDistance away from
+// it, and ends at the last reading where it moved once it stayed within MinDistance of it for StopAfter. Movement
+// is measured from these readings rather than between consecutive readings, so frequent readings of a slow vehicle
+// still make a trip. Readings older than the previous one are ignored. When the odometer decreases, i.e. after a
+// rollback or a replaced instrument cluster, the distance since the previous reading is measured between their
+// locations, or is 0 when one of them has no location, and the following deltas are measured from the new value.
+type Detector interface {
+	// Add feeds the next reading and returns the trip it ended, if any.
+	Add(reading *Reading) *Trip
+	// Flush ends the trip in progress, if any, at the last reading where the vehicle moved.
+	Flush() *Trip
+}
+
+// anchor is the odometer and location of the vehicle at the reading movement is measured from.
+type anchor struct {
+	time     time.Time
+	odometer *smartcar.Odometer
+	location *smartcar.Location
+}
+
+// detector implements the Detector interface.
+type detector struct {
+	minDistance float64
+	stopAfter   time.Duration
+	maxGap      time.Duration
+
+	// previous is the last reading added, odometer and location are the last ones known.
+	previous *Reading
+	odometer *smartcar.Odometer
+	location *smartcar.Location
+
+	// trip is the trip in progress. anchor is the last reading where the vehicle stood still, or where it last
+	// moved during a trip, pending the distance traveled since then and sparse whether some of it was traveled
+	// between readings further apart than maxGap.
+	trip    *Trip
+	anchor  *anchor
+	pending float64
+	sparse  bool
+}
+
+// NewDetector creates a Detector.
+func NewDetector(params *DetectorParams) Detector {
+	d := &detector{
+		minDistance: params.MinDistance,
+		stopAfter:   params.StopAfter,
+		maxGap:      params.MaxGap,
+	}
+	if d.minDistance <= 0 {
+		d.minDistance = defaultMinDistance
+	}
+	if d.stopAfter <= 0 {
+		d.stopAfter = defaultStopAfter
+	}
+	if d.maxGap <= 0 {
+		d.maxGap = defaultMaxGap
+	}
+	return d
+}
+
+// Add implements Detector.
+func (d *detector) Add(reading *Reading) *Trip {
+	if d.previous != nil && reading.Time.Before(d.previous.Time) {
+		return nil
+	}
+	meters, measured := d.step(reading)
+	previous := d.previous
+	d.previous = reading
+	if d.anchor == nil {
+		d.setAnchor(reading.Time)
+		return nil
+	}
+	if !measured {
+		// The first odometer or location of the vehicle is where it stood at the anchor.
+		if d.anchor.odometer == nil {
+			d.anchor.odometer = d.odometer
+		}
+		if d.anchor.location == nil {
+			d.anchor.location = d.location
+		}
+		return nil
+	}
+
+	d.pending += meters
+	d.sparse = d.sparse || (meters > 0 && reading.Time.Sub(previous.Time) > d.maxGap)
+	if d.displacement() >= d.minDistance {
+		if d.trip == nil {
+			d.trip = &Trip{Start: d.anchor.time, StartLocation: d.anchor.location, Distance: smartcar.Distance{Units: smartcar.Metric}}
+		}
+		d.trip.Distance.Value += d.pending / 1000
+		d.trip.End = reading.Time
+		d.trip.EndLocation = d.location
+		d.trip.Sparse = d.trip.Sparse || d.sparse
+		d.setAnchor(reading.Time)
+		return nil
+	}
+
+	stopped := reading.Time.Sub(d.anchor.time) >= d.stopAfter
+	if d.trip != nil {
+		if stopped {
+			return d.Flush()
+		}
+		return nil
+	}
+	// The vehicle still stands where it stood, or did not go anywhere for StopAfter.
+	if meters == 0 || stopped {
+		d.setAnchor(reading.Time)
+	}
+	return nil
+}
+
+// Flush implements Detector.
+func (d *detector) Flush() *Trip {
+	trip := d.trip
+	d.trip = nil
+	if d.previous != nil {
+		d.setAnchor(d.previous.Time)
+	}
+	return trip
+}
+
+// setAnchor measures the next movements from the last known odometer and location, at t.
+func (d *detector) setAnchor(t time.Time) {
+	d.anchor = &anchor{time: t, odometer: d.odometer, location: d.location}
+	d.pending = 0
+	d.sparse = false
+}
+
+// displacement returns how far, in meters, the vehicle is from the anchor, according to its odometer or its
+// location, whichever is further.
+func (d *detector) displacement() float64 {
+	meters := 0.0
+	if d.anchor.odometer != nil && d.odometer != nil {
+		if delta := (d.odometer.Distance.Kilometers() - d.anchor.odometer.Distance.Kilometers()) * 1000; delta > meters {
+			meters = delta
+		}
+	}
+	if d.anchor.location != nil && d.location != nil {
+		if distance := d.anchor.location.DistanceTo(d.location); distance > meters {
+			meters = distance
+		}
+	}
+	return meters
+}
+
+// step updates the last known odometer and location with reading, and returns how far, in meters, the vehicle
+// moved since then. It returns false when reading can't be compared with the last known odometer or location.
+func (d *detector) step(reading *Reading) (float64, bool) {
+	odometer, location := d.odometer, d.location
+	if reading.Odometer != nil {
+		d.odometer = reading.Odometer
+	}
+	if reading.Location != nil {
+		d.location = reading.Location
+	}
+
+	switch {
+	case odometer != nil && reading.Odometer != nil:
+		delta := reading.Odometer.Distance.Kilometers() - odometer.Distance.Kilometers()
+		if delta >= 0 {
+			return delta * 1000, true
+		}
+		// The odometer rolled back, the anchor is measured from the new value.
+		if d.anchor != nil {
+			d.anchor.odometer = reading.Odometer
+		}
+		if location != nil && reading.Location != nil {
+			return location.DistanceTo(reading.Location), true
+		}
+		return 0, true
+	case location != nil && reading.Location != nil:
+		return location.DistanceTo(reading.Location), true
+	}
+	return 0, false
+}
+
+// Replay runs the readings of source through a Detector and returns the trips. A trip still in progress at the end
+// of the history is flushed.
+func Replay(ctx context.Context, source Source, params *DetectorParams) ([]*Trip, error) {
+	d := NewDetector(params)
+	var trips []*Trip
+	for {
+		reading, err := source.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if trip := d.Add(reading); trip != nil {
+			trips = append(trips, trip)
+		}
+	}
+	if trip := d.Flush(); trip != nil {
+		trips = append(trips, trip)
+	}
+	return trips, nil
+}
@@ -0,0 +1,218 @@
+package trip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	smartcar "github.com/smartcar/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DetectorTestSuite struct {
+	suite.Suite
+	start  time.Time
+	params *DetectorParams
+}
+
+func (s *DetectorTestSuite) SetupTest() {
+	s.start = time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	s.params = &DetectorParams{}
+}
+
+// odometer returns an odometer reading minutes after the start of the test.
+func (s *DetectorTestSuite) odometer(minutes int, kilometers float64) *Reading {
+	return &Reading{
+		Time:     s.start.Add(time.Duration(minutes) * time.Minute),
+		Odometer: &smartcar.Odometer{Distance: smartcar.Distance{Value: kilometers, Units: smartcar.Metric}},
+	}
+}
+
+// located returns reading with a location latitude degrees north of (0, 0).
+func (s *DetectorTestSuite) located(reading *Reading, latitude float64) *Reading {
+	reading.Location = &smartcar.Location{Latitude: latitude}
+	return reading
+}
+
+func (s *DetectorTestSuite) replay(readings ...*Reading) []*Trip {
+	trips, err := Replay(context.Background(), NewSliceSource(readings), s.params)
+	assert.Nil(s.T(), err)
+	return trips
+}
+
+func (s *DetectorTestSuite) TestSingleTrip() {
+	trips := s.replay(
+		s.located(s.odometer(0, 1000), 0),
+		s.located(s.odometer(1, 1000), 0),
+		s.located(s.odometer(2, 1001), 0.01),
+		s.located(s.odometer(3, 1002.5), 0.02),
+		s.located(s.odometer(4, 1002.5), 0.02),
+		s.located(s.odometer(10, 1002.5), 0.02),
+	)
+
+	assert.Len(s.T(), trips, 1)
+	trip := trips[0]
+	assert.Equal(s.T(), s.start.Add(time.Minute), trip.Start)
+	assert.Equal(s.T(), s.start.Add(3*time.Minute), trip.End)
+	assert.Equal(s.T(), 2*time.Minute, trip.Duration())
+	assert.Equal(s.T(), 0.0, trip.StartLocation.Latitude)
+	assert.Equal(s.T(), 0.02, trip.EndLocation.Latitude)
+	assert.Equal(s.T(), smartcar.Metric, trip.Distance.Units)
+	assert.InDelta(s.T(), 2.5, trip.Distance.Value, 1e-9)
+	assert.False(s.T(), trip.Sparse)
+}
+
+func (s *DetectorTestSuite) TestStopSegmentsTrips() {
+	trips := s.replay(
+		s.odometer(0, 10),
+		s.odometer(1, 12),
+		// A short stop at a light does not end the trip.
+		s.odometer(3, 12),
+		s.odometer(4, 13),
+		s.odometer(10, 13),
+		s.odometer(20, 20),
+	)
+
+	assert.Len(s.T(), trips, 2)
+	assert.InDelta(s.T(), 3, trips[0].Distance.Value, 1e-9)
+	assert.Equal(s.T(), s.start.Add(4*time.Minute), trips[0].End)
+	assert.Equal(s.T(), s.start.Add(10*time.Minute), trips[1].Start)
+	assert.InDelta(s.T(), 7, trips[1].Distance.Value, 1e-9)
+}
+
+func (s *DetectorTestSuite) TestOdometerRollback() {
+	trips := s.replay(
+		s.odometer(0, 999990),
+		s.odometer(1, 999995),
+		// The odometer rolled over to 0 while driving.
+		s.odometer(2, 3),
+		s.odometer(3, 8),
+		s.odometer(20, 8),
+	)
+
+	assert.Len(s.T(), trips, 1)
+	assert.InDelta(s.T(), 10, trips[0].Distance.Value, 1e-9)
+	assert.Equal(s.T(), s.start.Add(3*time.Minute), trips[0].End)
+}
+
+func (s *DetectorTestSuite) TestOdometerRollbackFallsBackToLocation() {
+	trips := s.replay(
+		s.located(s.odometer(0, 500), 0),
+		// 0.01 degrees of latitude are about 1.1 kilometers.
+		s.located(s.odometer(1, 100), 0.01),
+		s.located(s.odometer(20, 100), 0.01),
+	)
+
+	assert.Len(s.T(), trips, 1)
+	assert.InDelta(s.T(), 1.112, trips[0].Distance.Value, 1e-3)
+}
+
+func (s *DetectorTestSuite) TestDensePolls() {
+	readings := []*Reading{
+		s.located(&Reading{Time: s.start}, 0),
+		s.located(&Reading{Time: s.start.Add(10 * time.Second)}, 0),
+	}
+	// 0.00075 degrees of latitude are about 83 meters every 10 seconds, less than MinDistance between readings.
+	for i := 1; i <= 30; i++ {
+		readings = append(readings, s.located(&Reading{Time: s.start.Add(time.Duration(i+1) * 10 * time.Second)}, float64(i)*0.00075))
+	}
+	readings = append(readings, s.located(&Reading{Time: s.start.Add(20 * time.Minute)}, 0.0225))
+
+	trips := s.replay(readings...)
+
+	assert.Len(s.T(), trips, 1)
+	assert.Equal(s.T(), s.start.Add(10*time.Second), trips[0].Start)
+	assert.Equal(s.T(), s.start.Add(310*time.Second), trips[0].End)
+	assert.InDelta(s.T(), 0.0225, trips[0].EndLocation.Latitude, 1e-9)
+	assert.InDelta(s.T(), 2.502, trips[0].Distance.Value, 1e-3)
+}
+
+func (s *DetectorTestSuite) TestSparsePolls() {
+	trips := s.replay(
+		s.odometer(0, 100),
+		s.odometer(120, 180),
+		s.odometer(121, 181),
+		s.odometer(180, 181),
+	)
+
+	assert.Len(s.T(), trips, 1)
+	assert.True(s.T(), trips[0].Sparse)
+	assert.Equal(s.T(), s.start, trips[0].Start)
+	assert.InDelta(s.T(), 81, trips[0].Distance.Value, 1e-9)
+}
+
+func (s *DetectorTestSuite) TestMixedReadings() {
+	trips := s.replay(
+		s.odometer(0, 100),
+		s.located(&Reading{Time: s.start.Add(time.Minute)}, 0),
+		// Odometer readings are compared with the last known odometer.
+		s.odometer(2, 105),
+		s.located(&Reading{Time: s.start.Add(3 * time.Minute)}, 0.01),
+		s.odometer(10, 105),
+	)
+
+	assert.Len(s.T(), trips, 1)
+	assert.InDelta(s.T(), 5+1.112, trips[0].Distance.Value, 1e-3)
+	assert.Equal(s.T(), s.start.Add(3*time.Minute), trips[0].End)
+}
+
+func (s *DetectorTestSuite) TestJitterAndOutOfOrderReadings() {
+	trips := s.replay(
+		s.located(&Reading{Time: s.start}, 0),
+		// About 30 meters of GPS jitter.
+		s.located(&Reading{Time: s.start.Add(time.Minute)}, 0.0003),
+		s.located(&Reading{Time: s.start.Add(2 * time.Minute)}, 0),
+		s.located(&Reading{Time: s.start.Add(time.Minute)}, 1),
+	)
+
+	assert.Empty(s.T(), trips)
+}
+
+func (s *DetectorTestSuite) TestFlushTripInProgress() {
+	d := NewDetector(s.params)
+
+	d.Add(s.odometer(0, 10))
+	ended := d.Add(s.odometer(1, 11))
+	trip := d.Flush()
+
+	assert.Nil(s.T(), ended)
+	assert.InDelta(s.T(), 1, trip.Distance.Value, 1e-9)
+	assert.Nil(s.T(), d.Flush())
+}
+
+func (s *DetectorTestSuite) TestDeterministic() {
+	readings := []*Reading{
+		s.odometer(0, 10),
+		s.odometer(1, 12),
+		s.odometer(10, 12),
+		s.odometer(11, 15),
+	}
+
+	assert.Equal(s.T(), s.replay(readings...), s.replay(readings...))
+}
+
+func (s *DetectorTestSuite) TestReplayError() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	trips, err := Replay(ctx, NewSliceSource([]*Reading{s.odometer(0, 10)}), s.params)
+
+	assert.Nil(s.T(), trips)
+	assert.Equal(s.T(), context.Canceled, err)
+}
+
+func (s *DetectorTestSuite) TestNewReading() {
+	age := s.start.Add(time.Hour)
+
+	fromOdometer := NewReading(&smartcar.Odometer{ResponseHeaders: smartcar.ResponseHeaders{DataAge: age}}, &smartcar.Location{})
+	fromLocation := NewReading(nil, &smartcar.Location{ResponseHeaders: smartcar.ResponseHeaders{DataAge: age}})
+
+	assert.Equal(s.T(), age, fromOdometer.Time)
+	assert.Equal(s.T(), age, fromLocation.Time)
+	assert.Nil(s.T(), fromLocation.Odometer)
+}
+
+func TestDetectorTestSuite(t *testing.T) {
+	suite.Run(t, new(DetectorTestSuite))
+}
@@ -0,0 +1,217 @@
+package smartcar
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+/*
+	Smartcar's API returns bare numbers whose meaning depends on the unit system of the response.
+	The following types keep the number together with the unit system it was reported in, so readings
+	from vehicles using different unit systems can be compared and converted locally.
+	An empty UnitSystem is treated as Metric, which is the default of Smartcar's API.
+*/
+
+// Conversion factors between the metric and imperial units used by Smartcar's API.
+const (
+	kilometersPerMile = 1.609344
+	litersPerGallon   = 3.785411784
+	kilopascalsPerPSI = 6.894757293168
+)
+
+// Distance is a distance in kilometers (Metric) or miles (Imperial).
+type Distance struct {
+	Value float64
+	Units UnitSystem
+}
+
+// Kilometers returns the distance in kilometers.
+func (d Distance) Kilometers() float64 {
+	return toMetric(d.Value, d.Units, kilometersPerMile)
+}
+
+// Miles returns the distance in miles.
+func (d Distance) Miles() float64 {
+	return toImperial(d.Value, d.Units, kilometersPerMile)
+}
+
+// In returns the distance converted to units.
+func (d Distance) In(units UnitSystem) Distance {
+	if isImperial(units) {
+		return Distance{Value: d.Miles(), Units: Imperial}
+	}
+	return Distance{Value: d.Kilometers(), Units: Metric}
+}
+
+// MarshalJSON encodes the distance as a bare number, the same way Smartcar's API does.
+func (d Distance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Value)
+}
+
+// UnmarshalJSON decodes a bare number into the distance. Units are set from the response headers.
+func (d *Distance) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &d.Value)
+}
+
+// Volume is a volume in liters (Metric) or US gallons (Imperial).
+type Volume struct {
+	Value float64
+	Units UnitSystem
+}
+
+// Liters returns the volume in liters.
+func (v Volume) Liters() float64 {
+	return toMetric(v.Value, v.Units, litersPerGallon)
+}
+
+// Gallons returns the volume in US gallons.
+func (v Volume) Gallons() float64 {
+	return toImperial(v.Value, v.Units, litersPerGallon)
+}
+
+// In returns the volume converted to units.
+func (v Volume) In(units UnitSystem) Volume {
+	if isImperial(units) {
+		return Volume{Value: v.Gallons(), Units: Imperial}
+	}
+	return Volume{Value: v.Liters(), Units: Metric}
+}
+
+// MarshalJSON encodes the volume as a bare number, the same way Smartcar's API does.
+func (v Volume) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Value)
+}
+
+// UnmarshalJSON decodes a bare number into the volume. Units are set from the response headers.
+func (v *Volume) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &v.Value)
+}
+
+// Pressure is a pressure in kilopascals (Metric) or pounds per square inch (Imperial).
+type Pressure struct {
+	Value float64
+	Units UnitSystem
+}
+
+// Kilopascals returns the pressure in kilopascals.
+func (p Pressure) Kilopascals() float64 {
+	return toMetric(p.Value, p.Units, kilopascalsPerPSI)
+}
+
+// PSI returns the pressure in pounds per square inch.
+func (p Pressure) PSI() float64 {
+	return toImperial(p.Value, p.Units, kilopascalsPerPSI)
+}
+
+// In returns the pressure converted to units.
+func (p Pressure) In(units UnitSystem) Pressure {
+	if isImperial(units) {
+		return Pressure{Value: p.PSI(), Units: Imperial}
+	}
+	return Pressure{Value: p.Kilopascals(), Units: Metric}
+}
+
+// MarshalJSON encodes the pressure as a bare number, the same way Smartcar's API does.
+func (p Pressure) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Value)
+}
+
+// UnmarshalJSON decodes a bare number into the pressure. Units are set from the response headers.
+func (p *Pressure) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &p.Value)
+}
+
+// Speed is a speed in kilometers per hour (Metric) or miles per hour (Imperial).
+type Speed struct {
+	Value float64
+	Units UnitSystem
+}
+
+// KilometersPerHour returns the speed in kilometers per hour.
+func (s Speed) KilometersPerHour() float64 {
+	return toMetric(s.Value, s.Units, kilometersPerMile)
+}
+
+// MilesPerHour returns the speed in miles per hour.
+func (s Speed) MilesPerHour() float64 {
+	return toImperial(s.Value, s.Units, kilometersPerMile)
+}
+
+// In returns the speed converted to units.
+func (s Speed) In(units UnitSystem) Speed {
+	if isImperial(units) {
+		return Speed{Value: s.MilesPerHour(), Units: Imperial}
+	}
+	return Speed{Value: s.KilometersPerHour(), Units: Metric}
+}
+
+// MarshalJSON encodes the speed as a bare number, the same way Smartcar's API does.
+func (s Speed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Value)
+}
+
+// UnmarshalJSON decodes a bare number into the speed. Units are set from the response headers.
+func (s *Speed) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &s.Value)
+}
+
+func isImperial(units UnitSystem) bool {
+	return units == Imperial
+}
+
+// toMetric converts value from units to its metric unit, given how many metric units make up one imperial unit.
+func toMetric(value float64, units UnitSystem, factor float64) float64 {
+	if isImperial(units) {
+		return value * factor
+	}
+	return value
+}
+
+// toImperial converts value from units to its imperial unit, given how many metric units make up one imperial unit.
+func toImperial(value float64, units UnitSystem, factor float64) float64 {
+	if isImperial(units) {
+		return value
+	}
+	return value / factor
+}
+
+// unitSystemApplier is implemented by responses that contain unit quantities. applyUnitSystem copies the
+// UnitSystem of the response headers into every quantity of the response.
+type unitSystemApplier interface {
+	applyUnitSystem()
+}
+
+// applyUnitSystem sets the units of the quantities in target, if it has any.
+func applyUnitSystem(target interface{}) {
+	if applier, ok := target.(unitSystemApplier); ok {
+		applier.applyUnitSystem()
+	}
+}
+
+var (
+	distanceType = reflect.TypeOf(Distance{})
+	volumeType   = reflect.TypeOf(Volume{})
+	pressureType = reflect.TypeOf(Pressure{})
+	speedType    = reflect.TypeOf(Speed{})
+)
+
+// quantityDecodeHook is a mapstructure.DecodeHookFunc that turns the bare numbers of a batch response
+// body into unit quantities.
+func quantityDecodeHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	value, ok := data.(float64)
+	if !ok {
+		return data, nil
+	}
+
+	switch to {
+	case distanceType:
+		return Distance{Value: value}, nil
+	case volumeType:
+		return Volume{Value: value}, nil
+	case pressureType:
+		return Pressure{Value: value}, nil
+	case speedType:
+		return Speed{Value: value}, nil
+	}
+	return data, nil
+}
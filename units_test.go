@@ -0,0 +1,126 @@
+package smartcar
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceConversion(t *testing.T) {
+	// Arrange
+	metric := Distance{Value: 160.9344, Units: Metric}
+	imperial := Distance{Value: 100, Units: Imperial}
+
+	// Act & Assert
+	assert.InDelta(t, 100, metric.Miles(), 1e-9)
+	assert.InDelta(t, 160.9344, metric.Kilometers(), 1e-9)
+	assert.InDelta(t, 160.9344, imperial.Kilometers(), 1e-9)
+	assert.Equal(t, imperial, imperial.In(Imperial))
+	assert.InDelta(t, 160.9344, imperial.In(Metric).Value, 1e-9)
+	assert.Equal(t, Metric, imperial.In(Metric).Units)
+}
+
+func TestDistanceEmptyUnitsIsMetric(t *testing.T) {
+	// Arrange
+	distance := Distance{Value: 42}
+
+	// Act
+	kilometers := distance.Kilometers()
+
+	// Assert
+	assert.Equal(t, 42.0, kilometers)
+}
+
+func TestVolumeConversion(t *testing.T) {
+	// Arrange
+	metric := Volume{Value: 37.85411784, Units: Metric}
+	imperial := Volume{Value: 10, Units: Imperial}
+
+	// Act & Assert
+	assert.InDelta(t, 10, metric.Gallons(), 1e-9)
+	assert.InDelta(t, 37.85411784, imperial.Liters(), 1e-9)
+	assert.Equal(t, Volume{Value: 10, Units: Imperial}, metric.In(Imperial).In(Imperial))
+}
+
+func TestPressureConversion(t *testing.T) {
+	// Arrange
+	metric := Pressure{Value: 220, Units: Metric}
+	imperial := Pressure{Value: 32, Units: Imperial}
+
+	// Act & Assert
+	assert.InDelta(t, 31.908, metric.PSI(), 1e-3)
+	assert.InDelta(t, 220.632, imperial.Kilopascals(), 1e-3)
+	assert.InDelta(t, 220, metric.In(Imperial).In(Metric).Value, 1e-9)
+}
+
+func TestSpeedConversion(t *testing.T) {
+	// Arrange
+	metric := Speed{Value: 100, Units: Metric}
+
+	// Act
+	imperial := metric.In(Imperial)
+
+	// Assert
+	assert.InDelta(t, 62.137, imperial.Value, 1e-3)
+	assert.Equal(t, Imperial, imperial.Units)
+	assert.InDelta(t, 100, imperial.KilometersPerHour(), 1e-9)
+}
+
+func TestQuantityJSON(t *testing.T) {
+	// Arrange
+	odometer := &Odometer{
+		Distance:        Distance{Value: 1234.5, Units: Imperial},
+		ResponseHeaders: ResponseHeaders{UnitSystem: Imperial},
+	}
+
+	// Act
+	b, err := json.Marshal(odometer)
+	decoded := new(Odometer)
+	json.Unmarshal(b, decoded)
+	applyUnitSystem(decoded)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"distance":1234.5`)
+	assert.Equal(t, odometer, decoded)
+}
+
+func TestApplyUnitSystem(t *testing.T) {
+	// Arrange
+	tirePressure := &TirePressure{
+		FrontLeft:       Pressure{Value: 33},
+		BackRight:       Pressure{Value: 34},
+		ResponseHeaders: ResponseHeaders{UnitSystem: Imperial},
+	}
+	location := &Location{ResponseHeaders: ResponseHeaders{UnitSystem: Imperial}}
+
+	// Act
+	applyUnitSystem(tirePressure)
+	applyUnitSystem(location)
+	applyUnitSystem(&VIN{})
+
+	// Assert
+	assert.Equal(t, Imperial, tirePressure.FrontLeft.Units)
+	assert.Equal(t, Imperial, tirePressure.BackRight.Units)
+	assert.Nil(t, location.Speed)
+}
+
+func TestDecodeBatchBody(t *testing.T) {
+	// Arrange
+	body := map[string]interface{}{
+		"amountRemaining":  40.0,
+		"percentRemaining": 0.5,
+		"range":            320.0,
+	}
+	fuel := new(Fuel)
+
+	// Act
+	err := decodeBatchBody(body, fuel)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Volume{Value: 40}, fuel.AmountRemaining)
+	assert.Equal(t, 0.5, fuel.PercentRemaining)
+	assert.Equal(t, Distance{Value: 320}, fuel.Range)
+}
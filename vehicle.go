@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -21,29 +25,37 @@ type Key string
 
 // Helper types to use in vehicle.Batch()
 const (
-	BatteryPath         Key = "/battery"
-	BatteryCapacityPath Key = "/battery/capacity"
-	ChargePath          Key = "/charge"
-	FuelPath            Key = "/fuel"
-	InfoPath            Key = "/"
-	LocationPath        Key = "/location"
-	OdometerPath        Key = "/odometer"
-	OilPath             Key = "/engine/oil"
-	PermissionsPath     Key = "/permissions"
-	TirePressurePath    Key = "/tires/pressure"
-	VINPath             Key = "/vin"
+	BatteryPath           Key = "/battery"
+	BatteryCapacityPath   Key = "/battery/capacity"
+	ChargePath            Key = "/charge"
+	ChargeAmperagePath    Key = "/charge/amperage"
+	ChargeCompletionPath  Key = "/charge/completion"
+	ChargeEnergyAddedPath Key = "/charge/energy_added"
+	ChargeRatePath        Key = "/charge/rate"
+	ChargeVoltagePath     Key = "/charge/voltage"
+	ChargerTypePath       Key = "/charge/charger_type"
+	FuelPath              Key = "/fuel"
+	IgnitionPath          Key = "/engine/ignition"
+	InfoPath              Key = "/"
+	LocationPath          Key = "/location"
+	OdometerPath          Key = "/odometer"
+	OilPath               Key = "/engine/oil"
+	PermissionsPath       Key = "/permissions"
+	TirePressurePath      Key = "/tires/pressure"
+	VINPath               Key = "/vin"
 
 	// DO NOT export the paths that are not supported by Batch.
 	securityPath      Key = "/security"
 	chargeControlPath Key = "/charge"
 	applicationPath   Key = "/application"
 	batchPath         Key = "/batch"
+	webhooksPath      Key = "/webhooks/"
 )
 
 // Battery formats response returned from vehicle.GetBattery().
 type Battery struct {
-	PercentRemaining float64 `json:"percentRemaining"`
-	Range            float64 `json:"range"`
+	PercentRemaining float64  `json:"percentRemaining"`
+	Range            Distance `json:"range"`
 	ResponseHeaders
 }
 
@@ -60,19 +72,83 @@ type Charge struct {
 	ResponseHeaders
 }
 
+// ChargeAmperage formats response returned from vehicle.GetChargeAmperage().
+// Amperage is the current drawn by the vehicle while charging, in amperes.
+type ChargeAmperage struct {
+	Amperage float64 `json:"amperage"`
+	ResponseHeaders
+}
+
+// ChargeCompletion formats response returned from vehicle.GetChargeCompletion().
+// TimeToComplete is the estimated number of minutes until the charge limit is reached.
+type ChargeCompletion struct {
+	TimeToComplete float64 `json:"timeToComplete"`
+	ResponseHeaders
+}
+
+// Duration returns TimeToComplete as a time.Duration.
+func (c *ChargeCompletion) Duration() time.Duration {
+	return time.Duration(c.TimeToComplete * float64(time.Minute))
+}
+
+// ChargeEnergyAdded formats response returned from vehicle.GetChargeEnergyAdded().
+// EnergyAdded is the energy added during the current (or last) charging session, in kilowatt-hours.
+type ChargeEnergyAdded struct {
+	EnergyAdded float64 `json:"energyAdded"`
+	ResponseHeaders
+}
+
+// ChargeRate formats response returned from vehicle.GetChargeRate().
+// Power is the charging power in kilowatts. RangeRate is the range added per hour of charging.
+type ChargeRate struct {
+	Power     float64 `json:"power"`
+	RangeRate Speed   `json:"rangeRate"`
+	ResponseHeaders
+}
+
+// ChargeVoltage formats response returned from vehicle.GetChargeVoltage().
+// Voltage is the potential difference of the charger, in volts.
+type ChargeVoltage struct {
+	Voltage float64 `json:"voltage"`
+	ResponseHeaders
+}
+
+// ChargerType formats response returned from vehicle.GetChargerType().
+// Type is one of the ChargerType* constants.
+type ChargerType struct {
+	Type string `json:"type"`
+	ResponseHeaders
+}
+
+// Charger types returned in ChargerType.Type.
+const (
+	ChargerTypeAC      = "AC"
+	ChargerTypeDCFast  = "DC_FAST"
+	ChargerTypeUnknown = "UNKNOWN"
+)
+
 // Data formats responses returned from vehicle.Batch().
 type Data struct {
-	Battery         *Battery         `json:"battery,omitempty"`
-	BatteryCapacity *BatteryCapacity `json:"batteryCapacity,omitempty"`
-	Charge          *Charge          `json:"charge,omitempty"`
-	Fuel            *Fuel            `json:"fuel,omitempty"`
-	Info            *Info            `json:"info,omitempty"`
-	Location        *Location        `json:"location,omitempty"`
-	Odometer        *Odometer        `json:"odometer,omitempty"`
-	Oil             *Oil             `json:"oil,omitempty"`
-	Permissions     *Permissions     `json:"permissions,omitempty"`
-	TirePressure    *TirePressure    `json:"tirePressure,omitempty"`
-	VIN             *VIN             `json:"vin,omitempty"`
+	Battery           *Battery           `json:"battery,omitempty"`
+	BatteryCapacity   *BatteryCapacity   `json:"batteryCapacity,omitempty"`
+	Charge            *Charge            `json:"charge,omitempty"`
+	ChargeAmperage    *ChargeAmperage    `json:"chargeAmperage,omitempty"`
+	ChargeCompletion  *ChargeCompletion  `json:"chargeCompletion,omitempty"`
+	ChargeEnergyAdded *ChargeEnergyAdded `json:"chargeEnergyAdded,omitempty"`
+	ChargeRate        *ChargeRate        `json:"chargeRate,omitempty"`
+	ChargeVoltage     *ChargeVoltage     `json:"chargeVoltage,omitempty"`
+	ChargerType       *ChargerType       `json:"chargerType,omitempty"`
+	Fuel              *Fuel              `json:"fuel,omitempty"`
+	Ignition          *Ignition          `json:"ignition,omitempty"`
+	Info              *Info              `json:"info,omitempty"`
+	Location          *Location          `json:"location,omitempty"`
+	Odometer          *Odometer          `json:"odometer,omitempty"`
+	Oil               *Oil               `json:"oil,omitempty"`
+	Permissions       *Permissions       `json:"permissions,omitempty"`
+	TirePressure      *TirePressure      `json:"tirePressure,omitempty"`
+	VIN               *VIN               `json:"vin,omitempty"`
+	// Errors are the errors of the paths that failed, their field above is nil.
+	Errors map[Key]*Error `json:"errors,omitempty"`
 }
 
 // Disconnect formats response returned from vehicle.Disconnect().
@@ -83,12 +159,27 @@ type Disconnect struct {
 
 // Fuel formats response returned from vehicle.GetFuel().
 type Fuel struct {
-	AmountRemaining  float64 `json:"amountRemaining"`
-	PercentRemaining float64 `json:"percentRemaining"`
-	Range            float64 `json:"range"`
+	AmountRemaining  Volume   `json:"amountRemaining"`
+	PercentRemaining float64  `json:"percentRemaining"`
+	Range            Distance `json:"range"`
 	ResponseHeaders
 }
 
+// Ignition formats response returned from vehicle.GetIgnition().
+// State is one of the Ignition* constants.
+type Ignition struct {
+	State           string `json:"state"`
+	IsEngineRunning bool   `json:"isEngineRunning"`
+	ResponseHeaders
+}
+
+// Ignition states returned in Ignition.State.
+const (
+	IgnitionOff       = "OFF"
+	IgnitionAccessory = "ACCESSORY"
+	IgnitionOn        = "ON"
+)
+
 // Info formats response returned from vehicle.GetInfo().
 type Info struct {
 	ID    string `json:"id"`
@@ -99,15 +190,39 @@ type Info struct {
 }
 
 // Location formats response returned from vehicle.GetLocation().
+// Heading, Speed and Accuracy are only set when the vehicle reports them. Heading is in degrees
+// clockwise from true north and Accuracy is the radius of uncertainty of the GPS fix, in meters.
 type Location struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Heading   *float64 `json:"heading,omitempty"`
+	Speed     *Speed   `json:"speed,omitempty"`
+	Accuracy  *float64 `json:"accuracy,omitempty"`
 	ResponseHeaders
 }
 
+// IsMoving reports whether the vehicle reported a speed greater than zero.
+// Vehicles that do not report a speed are never considered moving.
+func (l *Location) IsMoving() bool {
+	return l.Speed != nil && l.Speed.Value > 0
+}
+
+// earthRadius is the mean radius of the Earth, in meters.
+const earthRadius = 6371008.8
+
+// DistanceTo returns the great-circle distance between two locations, in meters.
+func (l *Location) DistanceTo(other *Location) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
 // Odometer formats response returned from vehicle.GetOdometer().
 type Odometer struct {
-	Distance float64 `json:"distance"`
+	Distance Distance `json:"distance"`
 	ResponseHeaders
 }
 
@@ -125,10 +240,10 @@ type Permissions struct {
 
 // TirePressure formats response returned from vehicle.GetTirePressure().
 type TirePressure struct {
-	FrontLeft  float64 `json:"frontLeft"`
-	FrontRight float64 `json:"frontRight"`
-	BackLeft   float64 `json:"backLeft"`
-	BackRight  float64 `json:"backRight"`
+	FrontLeft  Pressure `json:"frontLeft"`
+	FrontRight Pressure `json:"frontRight"`
+	BackLeft   Pressure `json:"backLeft"`
+	BackRight  Pressure `json:"backRight"`
 	ResponseHeaders
 }
 
@@ -150,6 +265,19 @@ type ChargeControl struct {
 	ResponseHeaders
 }
 
+// Subscribe formats response returned from vehicle.Subscribe().
+type Subscribe struct {
+	WebhookID string `json:"webhookId"`
+	VehicleID string `json:"vehicleId"`
+	ResponseHeaders
+}
+
+// Unsubscribe formats response returned from vehicle.Unsubscribe().
+type Unsubscribe struct {
+	Status string `json:"status"`
+	ResponseHeaders
+}
+
 // UnitSystem type that will have either imperic or metric.
 type UnitSystem string
 
@@ -170,52 +298,194 @@ type UnitsParams struct {
 	Units UnitSystem
 }
 
+func (b *Battery) applyUnitSystem() {
+	b.Range.Units = b.UnitSystem
+}
+
+func (c *ChargeRate) applyUnitSystem() {
+	c.RangeRate.Units = c.UnitSystem
+}
+
+func (f *Fuel) applyUnitSystem() {
+	f.AmountRemaining.Units = f.UnitSystem
+	f.Range.Units = f.UnitSystem
+}
+
+func (l *Location) applyUnitSystem() {
+	if l.Speed != nil {
+		l.Speed.Units = l.UnitSystem
+	}
+}
+
+func (o *Odometer) applyUnitSystem() {
+	o.Distance.Units = o.UnitSystem
+}
+
+func (t *TirePressure) applyUnitSystem() {
+	t.FrontLeft.Units = t.UnitSystem
+	t.FrontRight.Units = t.UnitSystem
+	t.BackLeft.Units = t.UnitSystem
+	t.BackRight.Units = t.UnitSystem
+}
+
 // Vehicle is an interface that contains all public methods available for vehicle. vehicle needs to implement
 // this methods to be able to expose them.
 type Vehicle interface {
 	Batch(context.Context, ...Key) (*Data, error)
-	Disconnect(context.Context) (*Disconnect, error)
-	GetBattery(context.Context) (*Battery, error)
-	GetBatteryCapacity(context.Context) (*BatteryCapacity, error)
-	GetCharge(context.Context) (*Charge, error)
-	GetFuel(context.Context) (*Fuel, error)
-	GetInfo(context.Context) (*Info, error)
-	GetLocation(context.Context) (*Location, error)
-	GetOdometer(context.Context) (*Odometer, error)
-	GetOil(context.Context) (*Oil, error)
-	GetPermissions(context.Context) (*Permissions, error)
-	GetTiresPressure(context.Context) (*TirePressure, error)
-	GetVIN(context.Context) (*VIN, error)
-	Lock(context.Context) (*Security, error)
+	BatchWithOptions(context.Context, []Key, ...RequestOption) (*Data, error)
+	Disconnect(context.Context, ...RequestOption) (*Disconnect, error)
+	GetBattery(context.Context, ...RequestOption) (*Battery, error)
+	GetBatteryCapacity(context.Context, ...RequestOption) (*BatteryCapacity, error)
+	GetCharge(context.Context, ...RequestOption) (*Charge, error)
+	GetChargeAmperage(context.Context, ...RequestOption) (*ChargeAmperage, error)
+	GetChargeCompletion(context.Context, ...RequestOption) (*ChargeCompletion, error)
+	GetChargeEnergyAdded(context.Context, ...RequestOption) (*ChargeEnergyAdded, error)
+	GetChargeRate(context.Context, ...RequestOption) (*ChargeRate, error)
+	GetChargeVoltage(context.Context, ...RequestOption) (*ChargeVoltage, error)
+	GetChargerType(context.Context, ...RequestOption) (*ChargerType, error)
+	GetFuel(context.Context, ...RequestOption) (*Fuel, error)
+	GetIgnition(context.Context, ...RequestOption) (*Ignition, error)
+	GetInfo(context.Context, ...RequestOption) (*Info, error)
+	GetLocation(context.Context, ...RequestOption) (*Location, error)
+	GetOdometer(context.Context, ...RequestOption) (*Odometer, error)
+	GetOil(context.Context, ...RequestOption) (*Oil, error)
+	GetPermissions(context.Context, ...RequestOption) (*Permissions, error)
+	GetTiresPressure(context.Context, ...RequestOption) (*TirePressure, error)
+	GetVIN(context.Context, ...RequestOption) (*VIN, error)
+	Lock(context.Context, ...RequestOption) (*Security, error)
 	SetUnitSystem(*UnitsParams) error
-	Unlock(context.Context) (*Security, error)
-	StartCharge(context.Context) (*ChargeControl, error)
-	StopCharge(context.Context) (*ChargeControl, error)
+	Subscribe(ctx context.Context, webhookID string) (*Subscribe, error)
+	Unlock(context.Context, ...RequestOption) (*Security, error)
+	Unsubscribe(ctx context.Context, managementToken, webhookID string) (*Unsubscribe, error)
+	StartCharge(context.Context, ...RequestOption) (*ChargeControl, error)
+	StopCharge(context.Context, ...RequestOption) (*ChargeControl, error)
 }
 
-// vehicle client that implements the Vehicle interface.
+// vehicle client that implements the Vehicle interface. It is safe for concurrent use, requestParams
+// are guarded by mu so SetUnitSystem can be called while other requests are in flight.
 type vehicle struct {
 	requestParams
+	mu          sync.RWMutex
 	id          string
 	accessToken string
 	client      backendClient
 }
 
 type batchResponse struct {
-	Responses []struct {
-		Path    string
-		Code    int
-		Headers struct {
-			DataAge    string     `json:"sc-data-age,omitempty"`
-			RequestID  string     `json:"sc-request-id,omitempty"`
-			UnitSystem UnitSystem `json:"sc-unit-system,omitempty"`
-		} `json:"headers,omitempty"`
-		Body interface{} `json:"body"`
-	} `json:"responses"`
+	Responses []batchResponseItem `json:"responses"`
+}
+
+// batchResponseItem is the response of a single path of a batch request. Webhook deliveries use the same format.
+type batchResponseItem struct {
+	Path    string
+	Code    int
+	Headers struct {
+		DataAge    string     `json:"sc-data-age,omitempty"`
+		RequestID  string     `json:"sc-request-id,omitempty"`
+		UnitSystem UnitSystem `json:"sc-unit-system,omitempty"`
+	} `json:"headers,omitempty"`
+	Body interface{} `json:"body"`
+}
+
+// batchItem is implemented by every response that can be part of a batch response.
+type batchItem interface {
+	responseHeaders() *ResponseHeaders
+}
+
+// decodeBatchBody decodes the body of a single batch response into target.
+func decodeBatchBody(body interface{}, target interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: quantityDecodeHook,
+		Result:     target,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(body)
+}
+
+// dataFields maps the paths supported by Batch to the field of Data that holds their response.
+var dataFields = map[Key]string{
+	BatteryPath:           "Battery",
+	BatteryCapacityPath:   "BatteryCapacity",
+	ChargePath:            "Charge",
+	ChargeAmperagePath:    "ChargeAmperage",
+	ChargeCompletionPath:  "ChargeCompletion",
+	ChargeEnergyAddedPath: "ChargeEnergyAdded",
+	ChargeRatePath:        "ChargeRate",
+	ChargeVoltagePath:     "ChargeVoltage",
+	ChargerTypePath:       "ChargerType",
+	FuelPath:              "Fuel",
+	IgnitionPath:          "Ignition",
+	InfoPath:              "Info",
+	LocationPath:          "Location",
+	OdometerPath:          "Odometer",
+	OilPath:               "Oil",
+	PermissionsPath:       "Permissions",
+	TirePressurePath:      "TirePressure",
+	VINPath:               "VIN",
+}
+
+// item returns the response of key, or nil if data does not have one.
+func (d *Data) item(key Key) batchItem {
+	field, ok := dataFields[key]
+	if !ok {
+		return nil
+	}
+	value := reflect.ValueOf(d).Elem().FieldByName(field)
+	if value.IsNil() {
+		return nil
+	}
+	return value.Interface().(batchItem)
+}
+
+// newItem sets the response of key to a new, empty response and returns it, or nil if key is not part of Data.
+func (d *Data) newItem(key Key) batchItem {
+	field, ok := dataFields[key]
+	if !ok {
+		return nil
+	}
+	value := reflect.ValueOf(d).Elem().FieldByName(field)
+	value.Set(reflect.New(value.Type().Elem()))
+	return value.Interface().(batchItem)
+}
+
+// decodeBatchResponses formats the responses of a batch request into Data. Paths that are not part of Data are ignored.
+// Failed paths are set in Data.Errors instead of being decoded.
+func decodeBatchResponses(responses []batchResponseItem) *Data {
+	data := new(Data)
+	for _, res := range responses {
+		if _, ok := dataFields[Key(res.Path)]; !ok {
+			continue
+		}
+		if err := batchItemError(res); err != nil {
+			if data.Errors == nil {
+				data.Errors = map[Key]*Error{}
+			}
+			data.Errors[Key(res.Path)] = err
+			continue
+		}
+		item := data.newItem(Key(res.Path))
+		decodeBatchBody(res.Body, item)
+		*item.responseHeaders() = ResponseHeaders{
+			DataAge:    parseDataAge(res.Headers.DataAge),
+			RequestID:  res.Headers.RequestID,
+			UnitSystem: res.Headers.UnitSystem,
+		}
+		applyUnitSystem(item)
+	}
+
+	return data
 }
 
 // Batch sends a request to Smartcar's API vehicle/batch endpoint.
+// Batch uses the unit system of the vehicle, see SetUnitSystem. Use BatchWithOptions to pass request options.
 func (v *vehicle) Batch(ctx context.Context, keys ...Key) (*Data, error) {
+	return v.BatchWithOptions(ctx, keys)
+}
+
+// BatchWithOptions is Batch with request options, i.e. WithUnits or WithMaxAge, which apply to every path.
+func (v *vehicle) BatchWithOptions(ctx context.Context, keys []Key, opts ...RequestOption) (*Data, error) {
 	var requests []map[string]string
 
 	for _, path := range keys {
@@ -228,130 +498,133 @@ func (v *vehicle) Batch(ctx context.Context, keys ...Key) (*Data, error) {
 	bufferedBody := bytes.NewBuffer([]byte(marshalBody))
 
 	target := new(batchResponse)
-	err := v.request(ctx, string(batchPath), http.MethodPost, v.requestParams, bufferedBody, target)
+	err := v.request(ctx, string(batchPath), http.MethodPost, bufferedBody, target, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	data := new(Data)
-	for _, v := range target.Responses {
-		switch v.Path {
-		case string(BatteryPath):
-			mapstructure.Decode(v.Body, &data.Battery)
-			mapstructure.Decode(v.Headers, &data.Battery.ResponseHeaders)
-		case string(BatteryCapacityPath):
-			mapstructure.Decode(v.Body, &data.BatteryCapacity)
-			mapstructure.Decode(v.Headers, &data.BatteryCapacity.ResponseHeaders)
-		case string(ChargePath):
-			mapstructure.Decode(v.Body, &data.Charge)
-			mapstructure.Decode(v.Headers, &data.Charge.ResponseHeaders)
-		case string(FuelPath):
-			mapstructure.Decode(v.Body, &data.Fuel)
-			mapstructure.Decode(v.Headers, &data.Fuel.ResponseHeaders)
-		case string(InfoPath):
-			mapstructure.Decode(v.Body, &data.Info)
-			mapstructure.Decode(v.Headers, &data.Info.ResponseHeaders)
-		case string(LocationPath):
-			mapstructure.Decode(v.Body, &data.Location)
-			mapstructure.Decode(v.Headers, &data.Location.ResponseHeaders)
-		case string(OdometerPath):
-			mapstructure.Decode(v.Body, &data.Odometer)
-			mapstructure.Decode(v.Headers, &data.Odometer.ResponseHeaders)
-		case string(OilPath):
-			mapstructure.Decode(v.Body, &data.Oil)
-			mapstructure.Decode(v.Headers, &data.Oil.ResponseHeaders)
-		case string(PermissionsPath):
-			mapstructure.Decode(v.Body, &data.Permissions)
-			mapstructure.Decode(v.Headers, &data.Permissions.ResponseHeaders)
-		case string(TirePressurePath):
-			mapstructure.Decode(v.Body, &data.TirePressure)
-			mapstructure.Decode(v.Headers, &data.TirePressure.ResponseHeaders)
-		case string(VINPath):
-			mapstructure.Decode(v.Body, &data.VIN)
-			mapstructure.Decode(v.Headers, &data.VIN.ResponseHeaders)
-		}
-	}
-
-	return data, nil
+	return decodeBatchResponses(target.Responses), nil
 }
 
 // Disconnect sends a request to Smartcar's API vehicle/application endpoint.
-func (v *vehicle) Disconnect(ctx context.Context) (*Disconnect, error) {
+func (v *vehicle) Disconnect(ctx context.Context, opts ...RequestOption) (*Disconnect, error) {
 	disconnect := &Disconnect{}
-	return disconnect, v.request(ctx, string(applicationPath), http.MethodDelete, v.requestParams, nil, disconnect)
+	return disconnect, v.request(ctx, string(applicationPath), http.MethodDelete, nil, disconnect, opts...)
 }
 
 // GetBattery sends a request to Smartcar's API vehicle/battery endpoint.
-func (v *vehicle) GetBattery(ctx context.Context) (*Battery, error) {
+func (v *vehicle) GetBattery(ctx context.Context, opts ...RequestOption) (*Battery, error) {
 	battery := &Battery{}
-	return battery, v.request(ctx, string(BatteryPath), http.MethodGet, v.requestParams, nil, battery)
+	return battery, v.request(ctx, string(BatteryPath), http.MethodGet, nil, battery, opts...)
 }
 
 // GetBatteryCapacity sends a request to Smartcar's API vehicle/battery/capacity endpoint.
-func (v *vehicle) GetBatteryCapacity(ctx context.Context) (*BatteryCapacity, error) {
+func (v *vehicle) GetBatteryCapacity(ctx context.Context, opts ...RequestOption) (*BatteryCapacity, error) {
 	batteryCapacity := &BatteryCapacity{}
-	return batteryCapacity, v.request(ctx, string(BatteryCapacityPath), http.MethodGet, v.requestParams, nil, batteryCapacity)
+	return batteryCapacity, v.request(ctx, string(BatteryCapacityPath), http.MethodGet, nil, batteryCapacity, opts...)
 }
 
 // GetCharge sends a request to Smartcar's API vehicle/charge endpoint.
-func (v *vehicle) GetCharge(ctx context.Context) (*Charge, error) {
+func (v *vehicle) GetCharge(ctx context.Context, opts ...RequestOption) (*Charge, error) {
 	charge := &Charge{}
-	return charge, v.request(ctx, string(ChargePath), http.MethodGet, v.requestParams, nil, charge)
+	return charge, v.request(ctx, string(ChargePath), http.MethodGet, nil, charge, opts...)
+}
+
+// GetChargeAmperage sends a request to Smartcar's API vehicle/charge/amperage endpoint.
+func (v *vehicle) GetChargeAmperage(ctx context.Context, opts ...RequestOption) (*ChargeAmperage, error) {
+	chargeAmperage := &ChargeAmperage{}
+	return chargeAmperage, v.request(ctx, string(ChargeAmperagePath), http.MethodGet, nil, chargeAmperage, opts...)
+}
+
+// GetChargeCompletion sends a request to Smartcar's API vehicle/charge/completion endpoint.
+func (v *vehicle) GetChargeCompletion(ctx context.Context, opts ...RequestOption) (*ChargeCompletion, error) {
+	chargeCompletion := &ChargeCompletion{}
+	return chargeCompletion, v.request(ctx, string(ChargeCompletionPath), http.MethodGet, nil, chargeCompletion, opts...)
+}
+
+// GetChargeEnergyAdded sends a request to Smartcar's API vehicle/charge/energy_added endpoint.
+func (v *vehicle) GetChargeEnergyAdded(ctx context.Context, opts ...RequestOption) (*ChargeEnergyAdded, error) {
+	chargeEnergyAdded := &ChargeEnergyAdded{}
+	return chargeEnergyAdded, v.request(ctx, string(ChargeEnergyAddedPath), http.MethodGet, nil, chargeEnergyAdded, opts...)
+}
+
+// GetChargeRate sends a request to Smartcar's API vehicle/charge/rate endpoint.
+func (v *vehicle) GetChargeRate(ctx context.Context, opts ...RequestOption) (*ChargeRate, error) {
+	chargeRate := &ChargeRate{}
+	return chargeRate, v.request(ctx, string(ChargeRatePath), http.MethodGet, nil, chargeRate, opts...)
+}
+
+// GetChargeVoltage sends a request to Smartcar's API vehicle/charge/voltage endpoint.
+func (v *vehicle) GetChargeVoltage(ctx context.Context, opts ...RequestOption) (*ChargeVoltage, error) {
+	chargeVoltage := &ChargeVoltage{}
+	return chargeVoltage, v.request(ctx, string(ChargeVoltagePath), http.MethodGet, nil, chargeVoltage, opts...)
+}
+
+// GetChargerType sends a request to Smartcar's API vehicle/charge/charger_type endpoint.
+func (v *vehicle) GetChargerType(ctx context.Context, opts ...RequestOption) (*ChargerType, error) {
+	chargerType := &ChargerType{}
+	return chargerType, v.request(ctx, string(ChargerTypePath), http.MethodGet, nil, chargerType, opts...)
 }
 
 // GetFuel sends a request to Smartcar's API vehicle/fuel endpoint.
-func (v *vehicle) GetFuel(ctx context.Context) (*Fuel, error) {
+func (v *vehicle) GetFuel(ctx context.Context, opts ...RequestOption) (*Fuel, error) {
 	fuel := &Fuel{}
-	return fuel, v.request(ctx, string(FuelPath), http.MethodGet, v.requestParams, nil, fuel)
+	return fuel, v.request(ctx, string(FuelPath), http.MethodGet, nil, fuel, opts...)
+}
+
+// GetIgnition sends a request to Smartcar's API vehicle/engine/ignition endpoint.
+func (v *vehicle) GetIgnition(ctx context.Context, opts ...RequestOption) (*Ignition, error) {
+	ignition := &Ignition{}
+	return ignition, v.request(ctx, string(IgnitionPath), http.MethodGet, nil, ignition, opts...)
 }
 
 // GetInfo sends a request to Smartcar's API vehicle/ endpoint.
-func (v *vehicle) GetInfo(ctx context.Context) (*Info, error) {
+func (v *vehicle) GetInfo(ctx context.Context, opts ...RequestOption) (*Info, error) {
 	info := &Info{}
-	return info, v.request(ctx, string(InfoPath), http.MethodGet, v.requestParams, nil, info)
+	return info, v.request(ctx, string(InfoPath), http.MethodGet, nil, info, opts...)
 }
 
 // GetLocation sends a request to Smartcar's API vehicle/location endpoint.
-func (v *vehicle) GetLocation(ctx context.Context) (*Location, error) {
+func (v *vehicle) GetLocation(ctx context.Context, opts ...RequestOption) (*Location, error) {
 	location := &Location{}
-	return location, v.request(ctx, string(LocationPath), http.MethodGet, v.requestParams, nil, location)
+	return location, v.request(ctx, string(LocationPath), http.MethodGet, nil, location, opts...)
 }
 
 // GetOdometer sends a request to Smartcar's API vehicle/odometer endpoint.
-func (v *vehicle) GetOdometer(ctx context.Context) (*Odometer, error) {
+func (v *vehicle) GetOdometer(ctx context.Context, opts ...RequestOption) (*Odometer, error) {
 	odometer := &Odometer{}
-	return odometer, v.request(ctx, string(OdometerPath), http.MethodGet, v.requestParams, nil, odometer)
+	return odometer, v.request(ctx, string(OdometerPath), http.MethodGet, nil, odometer, opts...)
 }
 
 // GetOil sends a request to Smartcar's API vehicle/oil endpoint.
-func (v *vehicle) GetOil(ctx context.Context) (*Oil, error) {
+func (v *vehicle) GetOil(ctx context.Context, opts ...RequestOption) (*Oil, error) {
 	oil := &Oil{}
-	return oil, v.request(ctx, string(OilPath), http.MethodGet, v.requestParams, nil, oil)
+	return oil, v.request(ctx, string(OilPath), http.MethodGet, nil, oil, opts...)
 }
 
 // GetPermissions sends a request to Smartcar's API vehicle/permissions endpoint.
-func (v *vehicle) GetPermissions(ctx context.Context) (*Permissions, error) {
+func (v *vehicle) GetPermissions(ctx context.Context, opts ...RequestOption) (*Permissions, error) {
 	permissions := &Permissions{}
-	return permissions, v.request(ctx, string(PermissionsPath), http.MethodGet, v.requestParams, nil, permissions)
+	return permissions, v.request(ctx, string(PermissionsPath), http.MethodGet, nil, permissions, opts...)
 }
 
 // GetTiresPressure sends a request to Smartcar's API vehicle/tires/pressure endpoint.
-func (v *vehicle) GetTiresPressure(ctx context.Context) (*TirePressure, error) {
+func (v *vehicle) GetTiresPressure(ctx context.Context, opts ...RequestOption) (*TirePressure, error) {
 	tirePressure := &TirePressure{}
-	return tirePressure, v.request(ctx, string(TirePressurePath), http.MethodGet, v.requestParams, nil, tirePressure)
+	return tirePressure, v.request(ctx, string(TirePressurePath), http.MethodGet, nil, tirePressure, opts...)
 }
 
 // GetVIN sends a request to Smartcar's API vehicle/vin endpoint.
-func (v *vehicle) GetVIN(ctx context.Context) (*VIN, error) {
+func (v *vehicle) GetVIN(ctx context.Context, opts ...RequestOption) (*VIN, error) {
 	vin := &VIN{}
-	return vin, v.request(ctx, string(VINPath), http.MethodGet, v.requestParams, nil, vin)
+	return vin, v.request(ctx, string(VINPath), http.MethodGet, nil, vin, opts...)
 }
 
 // Lock sends a request to Smartcar's API vehicle/lock endpoint.
-func (v *vehicle) Lock(ctx context.Context) (*Security, error) {
+func (v *vehicle) Lock(ctx context.Context, opts ...RequestOption) (*Security, error) {
 	body := bytes.NewBuffer([]byte(`{"action":"LOCK"}`))
 	lock := &Security{}
-	return lock, v.request(ctx, string(securityPath), http.MethodPost, v.requestParams, body, lock)
+	return lock, v.request(ctx, string(securityPath), http.MethodPost, body, lock, opts...)
 }
 
 /*
@@ -359,49 +632,94 @@ func (v *vehicle) Lock(ctx context.Context) (*Security, error) {
 		return the odometer in meters).
   Note: Does not send a request to Smartcar's API, it just changes the unitSystem of the vehicle instance.
 		Therefore sending a new request after calling this method, the response will return the data using the unitSystem set.
+		To use a different unit system for a single request, pass WithUnits to that request instead.
 */
 func (v *vehicle) SetUnitSystem(params *UnitsParams) error {
-	if !(params.Units == Imperial || params.Units == Metric) { //compare to actuall unit system || params.Units == "imperial") { // check here if its part of the map. go enum
-		err := fmt.Sprintf("Unit must be %s or %s", Metric, Imperial)
-		return errors.New(err)
+	if err := validateUnitSystem(params.Units); err != nil {
+		return err
 	}
+	v.mu.Lock()
 	v.requestParams.UnitSystem = params.Units
+	v.mu.Unlock()
 	return nil
 }
 
 // Unlock sends a request to Smartcar's API vehicle/unlock endpoint.
-func (v *vehicle) Unlock(ctx context.Context) (*Security, error) {
+func (v *vehicle) Unlock(ctx context.Context, opts ...RequestOption) (*Security, error) {
 	body := bytes.NewBuffer([]byte(`{"action":"UNLOCK"}`))
 	unlock := &Security{}
-	return unlock, v.request(ctx, string(securityPath), http.MethodPost, v.requestParams, body, unlock)
+	return unlock, v.request(ctx, string(securityPath), http.MethodPost, body, unlock, opts...)
+}
+
+// Subscribe sends a request to Smartcar's API vehicle/webhooks endpoint to subscribe the vehicle to a webhook.
+func (v *vehicle) Subscribe(ctx context.Context, webhookID string) (*Subscribe, error) {
+	subscribe := &Subscribe{}
+	return subscribe, v.request(ctx, string(webhooksPath)+url.PathEscape(webhookID), http.MethodPost, nil, subscribe)
+}
+
+// Unsubscribe sends a request to Smartcar's API vehicle/webhooks endpoint to unsubscribe the vehicle from a webhook.
+// Unlike the other vehicle requests, it is authorized with the application management token.
+func (v *vehicle) Unsubscribe(ctx context.Context, managementToken, webhookID string) (*Unsubscribe, error) {
+	unsubscribe := &Unsubscribe{}
+	authorization := buildBearerAuthorization(managementToken)
+	return unsubscribe, v.requestWithAuthorization(ctx, string(webhooksPath)+url.PathEscape(webhookID), http.MethodDelete, authorization, nil, unsubscribe)
 }
 
 // StartCharge sends a request to Smartcar's API to start charging on a vehicle.
-func (v *vehicle) StartCharge(ctx context.Context) (*ChargeControl, error) {
+func (v *vehicle) StartCharge(ctx context.Context, opts ...RequestOption) (*ChargeControl, error) {
 	body := bytes.NewBuffer([]byte(`{"action":"START"}`))
 	startcharge := &ChargeControl{}
-	return startcharge, v.request(ctx, string(chargeControlPath), http.MethodPost, v.requestParams, body, startcharge)
+	return startcharge, v.request(ctx, string(chargeControlPath), http.MethodPost, body, startcharge, opts...)
 }
 
 // StopCharge sends a request to Smartcar's API to stop charging on a vehicle.
-func (v *vehicle) StopCharge(ctx context.Context) (*ChargeControl, error) {
+func (v *vehicle) StopCharge(ctx context.Context, opts ...RequestOption) (*ChargeControl, error) {
 	body := bytes.NewBuffer([]byte(`{"action":"STOP"}`))
 	stopcharge := &ChargeControl{}
-	return stopcharge, v.request(ctx, string(chargeControlPath), http.MethodPost, v.requestParams, body, stopcharge)
+	return stopcharge, v.request(ctx, string(chargeControlPath), http.MethodPost, body, stopcharge, opts...)
 }
 
 /*
   request is an internal function used to make requests to Smartcar's vehicle API. It accepts an interface,
-  which is used to format the response.
+  which is used to format the response. opts are applied on a copy of the vehicle's requestParams, so they
+  only affect this request.
 */
-func (v *vehicle) request(ctx context.Context, path, method string, params requestParams, data io.Reader, target interface{}) error {
+func (v *vehicle) request(ctx context.Context, path, method string, data io.Reader, target interface{}, opts ...RequestOption) error {
+	return v.requestWithAuthorization(ctx, path, method, buildBearerAuthorization(v.accessToken), data, target, opts...)
+}
+
+// requestWithAuthorization is request, authorized with authorization instead of the vehicle's access token.
+func (v *vehicle) requestWithAuthorization(ctx context.Context, path, method, authorization string, data io.Reader, target interface{}, opts ...RequestOption) error {
+	v.mu.RLock()
+	params := v.requestParams
+	v.mu.RUnlock()
+
+	for _, opt := range opts {
+		opt(&params)
+	}
+	if params.UnitSystem != "" {
+		if err := validateUnitSystem(params.UnitSystem); err != nil {
+			return err
+		}
+	}
+
 	return v.client.Call(backendClientParams{
 		ctx:           ctx,
 		method:        method,
 		url:           buildVehicleURL(path, v.id),
-		authorization: buildBearerAuthorization(v.accessToken),
+		authorization: authorization,
+		vehicleID:     v.id,
+		path:          path,
 		requestParams: params,
 		body:          data,
 		target:        target,
 	})
 }
+
+// validateUnitSystem checks that units is one of the supported unit systems.
+func validateUnitSystem(units UnitSystem) error {
+	if _, ok := unitSystems[string(units)]; !ok {
+		return fmt.Errorf("Unit must be %s or %s", Metric, Imperial)
+	}
+	return nil
+}
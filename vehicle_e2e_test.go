@@ -3,6 +3,7 @@ package smartcar
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -23,12 +24,12 @@ func (s *VehicleE2ETestSuite) SetupTest() {
 		accessToken: "access-token",
 		client:      newBackend(),
 	}
-	s.mockAge = "data-age"
+	s.mockAge = "2018-06-20T01:33:37.078Z"
 	s.mockRequestID = "request-id"
 	s.mockUnitSystem = Metric
 	s.responseHeaders = ResponseHeaders{
 		Age:        s.mockAge,
-		DataAge:    s.mockAge,
+		DataAge:    time.Date(2018, 6, 20, 1, 33, 37, 78000000, time.UTC),
 		RequestID:  s.mockRequestID,
 		UnitSystem: s.mockUnitSystem,
 	}
@@ -54,9 +55,9 @@ func (s *VehicleE2ETestSuite) TestBatchE2E() {
 	mockDistance := 37829.0
 	expectedResponse := &Data{
 		Odometer: &Odometer{
-			Distance: mockDistance,
+			Distance: Distance{Value: mockDistance, Units: s.responseHeaders.UnitSystem},
 			ResponseHeaders: ResponseHeaders{
-				DataAge:    s.responseHeaders.Age,
+				DataAge:    s.responseHeaders.DataAge,
 				UnitSystem: s.responseHeaders.UnitSystem,
 			},
 		},
@@ -64,7 +65,7 @@ func (s *VehicleE2ETestSuite) TestBatchE2E() {
 			Latitude:  mockLatitude,
 			Longitude: mockLongitude,
 			ResponseHeaders: ResponseHeaders{
-				DataAge: s.responseHeaders.Age,
+				DataAge: s.responseHeaders.DataAge,
 			},
 		},
 	}
@@ -103,6 +104,175 @@ func (s *VehicleE2ETestSuite) TestBatchE2E() {
 	assert.Equal(s.T(), expectedResponse, res)
 }
 
+func (s *VehicleE2ETestSuite) TestBatchChargeE2E() {
+	mockPower := 7.2
+	mockRangeRate := 25.5
+	mockVoltage := 240.0
+	expectedResponse := &Data{
+		ChargeRate: &ChargeRate{
+			Power:     mockPower,
+			RangeRate: Speed{Value: mockRangeRate, Units: Imperial},
+			ResponseHeaders: ResponseHeaders{
+				DataAge:    s.responseHeaders.DataAge,
+				UnitSystem: Imperial,
+			},
+		},
+		ChargeVoltage: &ChargeVoltage{
+			Voltage: mockVoltage,
+			ResponseHeaders: ResponseHeaders{
+				DataAge: s.responseHeaders.DataAge,
+			},
+		},
+	}
+	mockURL := buildVehicleURL(string(batchPath), s.vehicle.id)
+	mockResponse := map[string]interface{}{
+		"responses": []interface{}{
+			map[string]interface{}{
+				"path": "/charge/rate",
+				"body": map[string]interface{}{
+					"power":     mockPower,
+					"rangeRate": mockRangeRate,
+				},
+				"code": 200,
+				"headers": map[string]interface{}{
+					"sc-data-age":    s.responseHeaders.Age,
+					"sc-unit-system": Imperial,
+				},
+			},
+			map[string]interface{}{
+				"path": "/charge/voltage",
+				"body": map[string]interface{}{
+					"voltage": mockVoltage,
+				},
+				"code": 200,
+				"headers": map[string]interface{}{
+					"sc-data-age": s.responseHeaders.Age,
+				},
+			},
+		},
+	}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.Batch(context.TODO(), ChargeRatePath, ChargeVoltagePath)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestBatchFailedPathE2E() {
+	mockURL := buildVehicleURL(string(batchPath), s.vehicle.id)
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, map[string]interface{}{
+		"responses": []interface{}{
+			map[string]interface{}{
+				"path": "/odometer",
+				"body": map[string]interface{}{"distance": 100.0},
+				"code": 200,
+			},
+			map[string]interface{}{
+				"path":    "/location",
+				"body":    map[string]interface{}{"type": "VEHICLE_STATE", "code": "ASLEEP", "description": "The vehicle is asleep."},
+				"code":    409,
+				"headers": map[string]interface{}{"sc-request-id": "location-id"},
+			},
+		},
+	})
+
+	res, err := s.vehicle.Batch(context.TODO(), OdometerPath, LocationPath)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 100.0, res.Odometer.Distance.Value)
+	assert.Nil(s.T(), res.Location)
+	assert.Equal(s.T(), map[Key]*Error{LocationPath: {
+		StatusCode: 409,
+		Type:       "VEHICLE_STATE",
+		Code:       "ASLEEP",
+		Message:    "The vehicle is asleep.",
+		RequestID:  "location-id",
+	}}, res.Errors)
+}
+
+func (s *VehicleE2ETestSuite) TestBatchWithOptionsE2E() {
+	mockURL := buildVehicleURL(string(batchPath), s.vehicle.id)
+	gock.New(mockURL).
+		MatchHeader("SC-Unit-System", string(Imperial)).
+		Reply(200).
+		JSON(map[string]interface{}{
+			"responses": []interface{}{
+				map[string]interface{}{
+					"path":    "/odometer",
+					"body":    map[string]interface{}{"distance": 100.0},
+					"code":    200,
+					"headers": map[string]interface{}{"sc-unit-system": Imperial},
+				},
+			},
+		})
+
+	res, err := s.vehicle.BatchWithOptions(context.TODO(), []Key{OdometerPath}, WithUnits(Imperial))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), Distance{Value: 100, Units: Imperial}, res.Odometer.Distance)
+}
+
+func (s *VehicleE2ETestSuite) TestBatchMotionE2E() {
+	mockSpeed := 31.0
+	mockHeading := 90.0
+	expectedResponse := &Data{
+		Ignition: &Ignition{
+			State:           IgnitionOn,
+			IsEngineRunning: true,
+			ResponseHeaders: ResponseHeaders{
+				DataAge: s.responseHeaders.DataAge,
+			},
+		},
+		Location: &Location{
+			Latitude:  1,
+			Longitude: 2,
+			Heading:   &mockHeading,
+			Speed:     &Speed{Value: mockSpeed, Units: Metric},
+			ResponseHeaders: ResponseHeaders{
+				DataAge:    s.responseHeaders.DataAge,
+				UnitSystem: Metric,
+			},
+		},
+	}
+	mockURL := buildVehicleURL(string(batchPath), s.vehicle.id)
+	mockResponse := map[string]interface{}{
+		"responses": []interface{}{
+			map[string]interface{}{
+				"path": "/engine/ignition",
+				"body": map[string]interface{}{
+					"state":           IgnitionOn,
+					"isEngineRunning": true,
+				},
+				"code": 200,
+				"headers": map[string]interface{}{
+					"sc-data-age": s.responseHeaders.Age,
+				},
+			},
+			map[string]interface{}{
+				"path": "/location",
+				"body": map[string]interface{}{
+					"latitude":  1,
+					"longitude": 2,
+					"heading":   mockHeading,
+					"speed":     mockSpeed,
+				},
+				"code": 200,
+				"headers": map[string]interface{}{
+					"sc-data-age":    s.responseHeaders.Age,
+					"sc-unit-system": Metric,
+				},
+			},
+		},
+	}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.Batch(context.TODO(), IgnitionPath, LocationPath)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
 func (s *VehicleE2ETestSuite) TestDisconnectE2E() {
 	mockStatus := "success"
 	expectedResponse := &Disconnect{
@@ -124,7 +294,7 @@ func (s *VehicleE2ETestSuite) TestGetBatteryE2E() {
 	mockRange := 40.5
 	expectedResponse := &Battery{
 		PercentRemaining: mockPercentRemaining,
-		Range:            mockRange,
+		Range:            Distance{Value: mockRange, Units: s.mockUnitSystem},
 		ResponseHeaders:  s.responseHeaders,
 	}
 	mockURL := buildVehicleURL(string(BatteryPath), s.vehicle.id)
@@ -171,14 +341,111 @@ func (s *VehicleE2ETestSuite) TestGetChargeE2E() {
 	assert.Equal(s.T(), expectedResponse, res)
 }
 
+func (s *VehicleE2ETestSuite) TestGetChargeAmperageE2E() {
+	mockAmperage := 32.0
+	expectedResponse := &ChargeAmperage{
+		Amperage:        mockAmperage,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(ChargeAmperagePath), s.vehicle.id)
+	mockResponse := map[string]interface{}{"amperage": mockAmperage}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetChargeAmperage(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestGetChargeCompletionE2E() {
+	mockTimeToComplete := 125.0
+	expectedResponse := &ChargeCompletion{
+		TimeToComplete:  mockTimeToComplete,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(ChargeCompletionPath), s.vehicle.id)
+	mockResponse := map[string]interface{}{"timeToComplete": mockTimeToComplete}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetChargeCompletion(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestGetChargeEnergyAddedE2E() {
+	mockEnergyAdded := 12.4
+	expectedResponse := &ChargeEnergyAdded{
+		EnergyAdded:     mockEnergyAdded,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(ChargeEnergyAddedPath), s.vehicle.id)
+	mockResponse := map[string]interface{}{"energyAdded": mockEnergyAdded}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetChargeEnergyAdded(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestGetChargeRateE2E() {
+	mockPower := 7.2
+	mockRangeRate := 41.0
+	expectedResponse := &ChargeRate{
+		Power:           mockPower,
+		RangeRate:       Speed{Value: mockRangeRate, Units: s.mockUnitSystem},
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(ChargeRatePath), s.vehicle.id)
+	mockResponse := map[string]interface{}{"power": mockPower, "rangeRate": mockRangeRate}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetChargeRate(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestGetChargeVoltageE2E() {
+	mockVoltage := 240.0
+	expectedResponse := &ChargeVoltage{
+		Voltage:         mockVoltage,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(ChargeVoltagePath), s.vehicle.id)
+	mockResponse := map[string]interface{}{"voltage": mockVoltage}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetChargeVoltage(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestGetChargerTypeE2E() {
+	expectedResponse := &ChargerType{
+		Type:            ChargerTypeDCFast,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(ChargerTypePath), s.vehicle.id)
+	mockResponse := map[string]interface{}{"type": ChargerTypeDCFast}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetChargerType(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
 func (s *VehicleE2ETestSuite) TestGetFuelE2E() {
 	mockAmountRemaining := 53.2
 	mockPercentRemaining := 0.3
 	mockRange := 40.5
 	expectedResponse := &Fuel{
-		AmountRemaining:  mockAmountRemaining,
+		AmountRemaining:  Volume{Value: mockAmountRemaining, Units: s.mockUnitSystem},
 		PercentRemaining: mockPercentRemaining,
-		Range:            mockRange,
+		Range:            Distance{Value: mockRange, Units: s.mockUnitSystem},
 		ResponseHeaders:  s.responseHeaders,
 	}
 	mockURL := buildVehicleURL(string(FuelPath), s.vehicle.id)
@@ -191,6 +458,23 @@ func (s *VehicleE2ETestSuite) TestGetFuelE2E() {
 	assert.Equal(s.T(), expectedResponse, res)
 }
 
+func (s *VehicleE2ETestSuite) TestGetIgnitionE2E() {
+	mockIsEngineRunning := true
+	expectedResponse := &Ignition{
+		State:           IgnitionOn,
+		IsEngineRunning: mockIsEngineRunning,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(IgnitionPath), s.vehicle.id)
+	mockResponse := map[string]interface{}{"state": IgnitionOn, "isEngineRunning": mockIsEngineRunning}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetIgnition(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
 func (s *VehicleE2ETestSuite) TestGetInfoE2E() {
 	mockYear := 2018
 	mockID := "1234"
@@ -231,10 +515,41 @@ func (s *VehicleE2ETestSuite) TestGetLocationE2E() {
 	assert.Equal(s.T(), expectedResponse, res)
 }
 
+func (s *VehicleE2ETestSuite) TestGetLocationMotionE2E() {
+	mockLatitude := 37.4292
+	mockLongitude := 122.1381
+	mockHeading := 271.5
+	mockSpeed := 88.0
+	mockAccuracy := 4.2
+	expectedResponse := &Location{
+		Latitude:        mockLatitude,
+		Longitude:       mockLongitude,
+		Heading:         &mockHeading,
+		Speed:           &Speed{Value: mockSpeed, Units: s.mockUnitSystem},
+		Accuracy:        &mockAccuracy,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(LocationPath), s.vehicle.id)
+	mockResponse := map[string]interface{}{
+		"latitude":  mockLatitude,
+		"longitude": mockLongitude,
+		"heading":   mockHeading,
+		"speed":     mockSpeed,
+		"accuracy":  mockAccuracy,
+	}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.GetLocation(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+	assert.True(s.T(), res.IsMoving())
+}
+
 func (s *VehicleE2ETestSuite) TestGetOdometerE2E() {
 	mockValue := 15444.0232
 	expectedResponse := &Odometer{
-		Distance:        mockValue,
+		Distance:        Distance{Value: mockValue, Units: s.mockUnitSystem},
 		ResponseHeaders: s.responseHeaders,
 	}
 	mockURL := buildVehicleURL(string(OdometerPath), s.vehicle.id)
@@ -285,10 +600,10 @@ func (s *VehicleE2ETestSuite) TestGetTiresPressureE2E() {
 	mockBackLeft := 219.0
 	mockBackRight := 219.0
 	expectedResponse := &TirePressure{
-		FrontLeft:       mockFrontLeft,
-		FrontRight:      mockFrontRight,
-		BackLeft:        mockBackLeft,
-		BackRight:       mockBackRight,
+		FrontLeft:       Pressure{Value: mockFrontLeft, Units: s.mockUnitSystem},
+		FrontRight:      Pressure{Value: mockFrontRight, Units: s.mockUnitSystem},
+		BackLeft:        Pressure{Value: mockBackLeft, Units: s.mockUnitSystem},
+		BackRight:       Pressure{Value: mockBackRight, Units: s.mockUnitSystem},
 		ResponseHeaders: s.responseHeaders,
 	}
 	mockURL := buildVehicleURL(string(TirePressurePath), s.vehicle.id)
@@ -354,6 +669,67 @@ func (s *VehicleE2ETestSuite) TestUnlockE2E() {
 	assert.Equal(s.T(), expectedResponse, res)
 }
 
+func (s *VehicleE2ETestSuite) TestSubscribeE2E() {
+	mockWebhookID := "webhook-id"
+	expectedResponse := &Subscribe{
+		WebhookID:       mockWebhookID,
+		VehicleID:       s.vehicle.id,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(webhooksPath)+mockWebhookID, s.vehicle.id)
+	mockResponse := map[string]interface{}{"webhookId": mockWebhookID, "vehicleId": s.vehicle.id}
+	mockVehicleAPI(mockURL, s.vehicle.accessToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.Subscribe(context.TODO(), mockWebhookID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestUnsubscribeE2E() {
+	mockWebhookID := "webhook-id"
+	mockManagementToken := "management-token"
+	mockStatus := "success"
+	expectedResponse := &Unsubscribe{
+		Status:          mockStatus,
+		ResponseHeaders: s.responseHeaders,
+	}
+	mockURL := buildVehicleURL(string(webhooksPath)+mockWebhookID, s.vehicle.id)
+	mockResponse := map[string]interface{}{"status": mockStatus}
+	mockVehicleAPI(mockURL, mockManagementToken, s.responseHeaders, mockResponse)
+
+	res, err := s.vehicle.Unsubscribe(context.TODO(), mockManagementToken, mockWebhookID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedResponse, res)
+}
+
+func (s *VehicleE2ETestSuite) TestSubscribeErrorE2E() {
+	mockWebhookID := "unknown-webhook-id"
+	expectedError := &Error{
+		StatusCode: 404,
+		Type:       "RESOURCE_NOT_FOUND",
+		Message:    "The webhook was not found.",
+		Code:       "RNF_000",
+		RequestID:  s.mockRequestID,
+	}
+	mockURL := buildVehicleURL(string(webhooksPath)+mockWebhookID, s.vehicle.id)
+	gock.New(mockURL).
+		MatchHeader("Authorization", buildBearerAuthorization(s.vehicle.accessToken)).
+		Reply(404).
+		SetHeader("Sc-Request-Id", s.mockRequestID).
+		JSON(map[string]interface{}{
+			"type":        expectedError.Type,
+			"description": expectedError.Message,
+			"code":        expectedError.Code,
+		})
+
+	_, err := s.vehicle.Subscribe(context.TODO(), mockWebhookID)
+
+	assert.Equal(s.T(), expectedError, err)
+	assert.EqualError(s.T(), err, "Not Found: RESOURCE_NOT_FOUND - The webhook was not found.")
+}
+
 func (s *VehicleE2ETestSuite) TestStartChargeE2E() {
 	mockStatus := "success"
 	expectedResponse := &ChargeControl{
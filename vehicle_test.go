@@ -2,7 +2,10 @@ package smartcar
 
 import (
 	"context"
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -24,6 +27,29 @@ func newFakeVehicleClient() backendClient {
 
 }
 
+// recordingVehicleClient records the unit system of every call it receives.
+type recordingVehicleClient struct {
+	mu          sync.Mutex
+	unitSystems []UnitSystem
+}
+
+func (c *recordingVehicleClient) Call(params backendClientParams) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unitSystems = append(c.unitSystems, params.requestParams.UnitSystem)
+	return nil
+}
+
+// jsonVehicleClient answers every call with the JSON body of its path, in the unit system of the request.
+type jsonVehicleClient struct {
+	responses map[string]string
+}
+
+func (c *jsonVehicleClient) Call(params backendClientParams) error {
+	headers := http.Header{"Sc-Unit-System": []string{string(params.requestParams.UnitSystem)}}
+	return (&backend{}).decode(&Response{StatusCode: http.StatusOK, Header: headers, Body: []byte(c.responses[params.path])}, params.target)
+}
+
 func (s *VehicleTestSuite) SetupTest() {
 	s.vehicle = vehicle{
 		id:          "client-id",
@@ -67,6 +93,79 @@ func (s *VehicleTestSuite) TestGetCharge() {
 	assert.NotNil(s.T(), res)
 }
 
+// chargeResponses are the bodies returned by a jsonVehicleClient for the charge endpoints.
+var chargeResponses = map[string]string{
+	string(ChargeAmperagePath):    `{"amperage":32}`,
+	string(ChargeCompletionPath):  `{"timeToComplete":90.5}`,
+	string(ChargeEnergyAddedPath): `{"energyAdded":12.5}`,
+	string(ChargeRatePath):        `{"power":7.2,"rangeRate":40}`,
+	string(ChargeVoltagePath):     `{"voltage":240}`,
+	string(ChargerTypePath):       `{"type":"DC_FAST"}`,
+}
+
+func (s *VehicleTestSuite) TestGetChargeAmperage() {
+	s.vehicle.client = &jsonVehicleClient{responses: chargeResponses}
+
+	res, err := s.vehicle.GetChargeAmperage(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 32.0, res.Amperage)
+}
+
+func (s *VehicleTestSuite) TestGetChargeCompletion() {
+	s.vehicle.client = &jsonVehicleClient{responses: chargeResponses}
+
+	res, err := s.vehicle.GetChargeCompletion(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 90.5, res.TimeToComplete)
+	assert.Equal(s.T(), 90*time.Minute+30*time.Second, res.Duration())
+}
+
+func (s *VehicleTestSuite) TestGetChargeEnergyAdded() {
+	s.vehicle.client = &jsonVehicleClient{responses: chargeResponses}
+
+	res, err := s.vehicle.GetChargeEnergyAdded(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 12.5, res.EnergyAdded)
+}
+
+func (s *VehicleTestSuite) TestGetChargeRate() {
+	s.vehicle.client = &jsonVehicleClient{responses: chargeResponses}
+
+	res, err := s.vehicle.GetChargeRate(context.TODO(), WithUnits(Imperial))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 7.2, res.Power)
+	assert.Equal(s.T(), 40.0, res.RangeRate.Value)
+	assert.Equal(s.T(), Imperial, res.RangeRate.Units)
+}
+
+func (s *VehicleTestSuite) TestGetChargeVoltage() {
+	s.vehicle.client = &jsonVehicleClient{responses: chargeResponses}
+
+	res, err := s.vehicle.GetChargeVoltage(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 240.0, res.Voltage)
+}
+
+func (s *VehicleTestSuite) TestGetChargerType() {
+	s.vehicle.client = &jsonVehicleClient{responses: chargeResponses}
+
+	res, err := s.vehicle.GetChargerType(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), ChargerTypeDCFast, res.Type)
+}
+
+func (s *VehicleTestSuite) TestChargeCompletionDuration() {
+	completion := &ChargeCompletion{TimeToComplete: 90.5}
+
+	assert.Equal(s.T(), 90*time.Minute+30*time.Second, completion.Duration())
+}
+
 func (s *VehicleTestSuite) TestGetFuel() {
 	res, err := s.vehicle.GetFuel(context.TODO())
 
@@ -74,6 +173,13 @@ func (s *VehicleTestSuite) TestGetFuel() {
 	assert.NotNil(s.T(), res)
 }
 
+func (s *VehicleTestSuite) TestGetIgnition() {
+	res, err := s.vehicle.GetIgnition(context.TODO())
+
+	assert.Nil(s.T(), err)
+	assert.NotNil(s.T(), res)
+}
+
 func (s *VehicleTestSuite) TestGetInfo() {
 	res, err := s.vehicle.GetInfo(context.TODO())
 
@@ -88,6 +194,21 @@ func (s *VehicleTestSuite) TestGetLocation() {
 	assert.NotNil(s.T(), res)
 }
 
+func (s *VehicleTestSuite) TestLocationIsMoving() {
+	assert.False(s.T(), (&Location{}).IsMoving())
+	assert.False(s.T(), (&Location{Speed: &Speed{Value: 0}}).IsMoving())
+	assert.True(s.T(), (&Location{Speed: &Speed{Value: 42.5}}).IsMoving())
+}
+
+func (s *VehicleTestSuite) TestLocationDistanceTo() {
+	paris := &Location{Latitude: 48.8566, Longitude: 2.3522}
+	london := &Location{Latitude: 51.5074, Longitude: -0.1278}
+
+	assert.InDelta(s.T(), 343500, paris.DistanceTo(london), 1000)
+	assert.InDelta(s.T(), paris.DistanceTo(london), london.DistanceTo(paris), 1e-6)
+	assert.Equal(s.T(), 0.0, paris.DistanceTo(paris))
+}
+
 func (s *VehicleTestSuite) TestGetOdometer() {
 	res, err := s.vehicle.GetOdometer(context.TODO())
 
@@ -148,6 +269,76 @@ func (s *VehicleTestSuite) TestSetUnitSystemError() {
 	assert.NotNil(s.T(), err)
 }
 
+func (s *VehicleTestSuite) TestWithUnits() {
+	recorder := &recordingVehicleClient{}
+	v := &vehicle{client: recorder, requestParams: requestParams{UnitSystem: Metric}}
+
+	_, err := v.GetOdometer(context.TODO(), WithUnits(Imperial))
+	assert.Nil(s.T(), err)
+	_, err = v.GetOdometer(context.TODO())
+	assert.Nil(s.T(), err)
+
+	assert.Equal(s.T(), []UnitSystem{Imperial, Metric}, recorder.unitSystems)
+	assert.Equal(s.T(), Metric, v.UnitSystem)
+}
+
+func (s *VehicleTestSuite) TestWithUnitsError() {
+	recorder := &recordingVehicleClient{}
+	v := &vehicle{client: recorder}
+
+	_, err := v.GetFuel(context.TODO(), WithUnits("furlongs"))
+
+	assert.NotNil(s.T(), err)
+	assert.Empty(s.T(), recorder.unitSystems)
+}
+
+// TestConcurrentUnitSystems is meant to be run with -race.
+func (s *VehicleTestSuite) TestConcurrentUnitSystems() {
+	recorder := &recordingVehicleClient{}
+	v := &vehicle{client: recorder, requestParams: requestParams{UnitSystem: Metric}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			v.GetOdometer(context.TODO(), WithUnits(Imperial))
+		}()
+		go func() {
+			defer wg.Done()
+			v.GetTiresPressure(context.TODO())
+		}()
+		go func(i int) {
+			defer wg.Done()
+			units := Metric
+			if i%2 == 0 {
+				units = Imperial
+			}
+			v.SetUnitSystem(&UnitsParams{Units: units})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(s.T(), recorder.unitSystems, 100)
+	for _, units := range recorder.unitSystems {
+		assert.Contains(s.T(), []UnitSystem{Metric, Imperial}, units)
+	}
+}
+
+func (s *VehicleTestSuite) TestSubscribe() {
+	res, err := s.vehicle.Subscribe(context.TODO(), "webhook-id")
+
+	assert.Nil(s.T(), err)
+	assert.NotNil(s.T(), res)
+}
+
+func (s *VehicleTestSuite) TestUnsubscribe() {
+	res, err := s.vehicle.Unsubscribe(context.TODO(), "management-token", "webhook-id")
+
+	assert.Nil(s.T(), err)
+	assert.NotNil(s.T(), res)
+}
+
 func (s *VehicleTestSuite) TestUnlock() {
 	res, err := s.vehicle.Unlock(context.TODO())
 
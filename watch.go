@@ -0,0 +1,318 @@
+package smartcar
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// Defaults used by Watch when no WatchOption overrides them.
+const (
+	defaultWatchInterval    = 5 * time.Minute
+	defaultWatchMinDistance = 50.0
+	defaultWatchJitter      = 0.1
+	defaultWatchMaxBackoff  = 10 * time.Minute
+)
+
+// maxWatchJitter caps the jitter of Watch, so waits are never shorter than a tenth of their duration.
+const maxWatchJitter = 0.9
+
+// WatchEvent is emitted by Watch. It is one of *LocationChange, *ChargeChange, *IgnitionChange, *OdometerChange,
+// *ReadingChange or *WatchError.
+type WatchEvent interface {
+	watchEvent()
+}
+
+// LocationChange is emitted when the vehicle moved at least the minimum distance (see WithMinDistance) away from
+// the location of the previous LocationChange, or from the first location read.
+type LocationChange struct {
+	Previous, Current *Location
+	// Meters is the distance between Previous and Current.
+	Meters float64
+}
+
+// ChargeChange is emitted when the charging state of the vehicle changed or it was plugged in or unplugged.
+type ChargeChange struct {
+	Previous, Current *Charge
+}
+
+// IgnitionChange is emitted when the ignition state of the vehicle changed or its engine started or stopped.
+type IgnitionChange struct {
+	Previous, Current *Ignition
+}
+
+// OdometerChange is emitted when the odometer of the vehicle advanced. Decreasing readings are ignored, the next
+// readings are compared with the highest one.
+type OdometerChange struct {
+	Previous, Current *Odometer
+	// Delta is the distance traveled between Previous and Current, in the units of Current.
+	Delta Distance
+}
+
+// ReadingChange is emitted when the reading of any other watched Key changed. Previous and Current are
+// pointers to the response type of Key (i.e. *Battery for BatteryPath).
+type ReadingChange struct {
+	Key               Key
+	Previous, Current interface{}
+}
+
+// WatchError is emitted when a Batch request failed. The watcher keeps polling after Retry.
+type WatchError struct {
+	Err error
+	// Attempt is the number of consecutive failed requests.
+	Attempt int
+	Retry   time.Duration
+}
+
+func (*LocationChange) watchEvent() {}
+func (*ChargeChange) watchEvent()   {}
+func (*IgnitionChange) watchEvent() {}
+func (*OdometerChange) watchEvent() {}
+func (*ReadingChange) watchEvent()  {}
+func (*WatchError) watchEvent()     {}
+
+// WatchOption configures Watch.
+type WatchOption func(*watcher)
+
+// WithMinDistance sets how far, in meters, the vehicle needs to move before a LocationChange is emitted.
+// Defaults to 50.
+func WithMinDistance(meters float64) WatchOption {
+	return func(w *watcher) {
+		w.minDistance = meters
+	}
+}
+
+// WithJitter randomizes every wait by up to fraction of its duration (i.e. 0.1 waits between 90% and 110% of
+// the interval), so many watchers do not poll Smartcar's API at the same time. Defaults to 0.1, and is clamped
+// between 0 and 0.9.
+func WithJitter(fraction float64) WatchOption {
+	return func(w *watcher) {
+		w.jitter = fraction
+	}
+}
+
+// WithMaxBackoff caps the wait between failed requests. The wait doubles with every consecutive failure,
+// starting from the interval. Defaults to 10 minutes, which is also used when maxBackoff is not positive.
+func WithMaxBackoff(maxBackoff time.Duration) WatchOption {
+	return func(w *watcher) {
+		w.maxBackoff = maxBackoff
+	}
+}
+
+// watcher holds the state of a single Watch call. It is only used by the goroutine started by Watch.
+type watcher struct {
+	vehicle     Vehicle
+	keys        []Key
+	interval    time.Duration
+	minDistance float64
+	jitter      float64
+	maxBackoff  time.Duration
+	random      *rand.Rand
+
+	// last is the last reading of every key, reference is the location of the last LocationChange.
+	last      map[Key]batchItem
+	reference *Location
+}
+
+// Watch calls vehicle.Batch with keys every interval and emits a WatchEvent on the returned channel for every change.
+// The first readings are used as a baseline and do not emit events. Readings whose DataAge is not newer than the
+// previous reading of the same key are ignored, so a vehicle that did not report new data never emits twice.
+// Failed requests emit a *WatchError and are retried with an exponential backoff, while the paths that failed in a
+// successful request (see Data.Errors) are ignored until they succeed. The channel is closed once ctx is done.
+// An interval that is not positive polls every 5 minutes.
+func Watch(ctx context.Context, vehicle Vehicle, keys []Key, interval time.Duration, opts ...WatchOption) <-chan WatchEvent {
+	w := newWatcher(vehicle, keys, interval, opts...)
+	events := make(chan WatchEvent)
+	go w.run(ctx, events)
+	return events
+}
+
+// newWatcher creates a watcher, replacing the settings that would poll in a tight loop with their defaults.
+func newWatcher(vehicle Vehicle, keys []Key, interval time.Duration, opts ...WatchOption) *watcher {
+	w := &watcher{
+		vehicle:     vehicle,
+		keys:        keys,
+		interval:    interval,
+		minDistance: defaultWatchMinDistance,
+		jitter:      defaultWatchJitter,
+		maxBackoff:  defaultWatchMaxBackoff,
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		last:        make(map[Key]batchItem),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.interval <= 0 {
+		w.interval = defaultWatchInterval
+	}
+	if w.maxBackoff <= 0 {
+		w.maxBackoff = defaultWatchMaxBackoff
+	}
+	w.jitter = math.Min(math.Max(w.jitter, 0), maxWatchJitter)
+	return w
+}
+
+// run polls until ctx is done.
+func (w *watcher) run(ctx context.Context, events chan<- WatchEvent) {
+	defer close(events)
+
+	failures := 0
+	for {
+		wait := w.interval
+		data, err := w.vehicle.Batch(ctx, w.keys...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			wait = w.backoff(failures)
+			if !emit(ctx, events, &WatchError{Err: err, Attempt: failures, Retry: wait}) {
+				return
+			}
+		} else {
+			failures = 0
+			for _, event := range w.changes(data) {
+				if !emit(ctx, events, event) {
+					return
+				}
+			}
+		}
+
+		timer := time.NewTimer(w.withJitter(wait))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// emit sends event, it returns false if ctx was done first.
+func emit(ctx context.Context, events chan<- WatchEvent, event WatchEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// backoff returns the wait after the given number of consecutive failures.
+func (w *watcher) backoff(failures int) time.Duration {
+	wait := w.interval
+	for i := 1; i < failures && wait < w.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > w.maxBackoff {
+		return w.maxBackoff
+	}
+	return wait
+}
+
+// withJitter randomizes wait by up to w.jitter of its duration.
+func (w *watcher) withJitter(wait time.Duration) time.Duration {
+	if w.jitter <= 0 {
+		return wait
+	}
+	return wait + time.Duration(float64(wait)*w.jitter*(2*w.random.Float64()-1))
+}
+
+// changes compares data with the previous readings and returns the events to emit.
+func (w *watcher) changes(data *Data) []WatchEvent {
+	var events []WatchEvent
+	for _, key := range w.keys {
+		current := data.item(key)
+		if current == nil {
+			continue
+		}
+		previous, seen := w.last[key]
+		if seen && (!isNewer(current, previous) || isRollback(previous, current)) {
+			continue
+		}
+		w.last[key] = current
+		if key == LocationPath {
+			if event := w.locationChange(current.(*Location)); event != nil {
+				events = append(events, event)
+			}
+			continue
+		}
+		if !seen {
+			continue
+		}
+		if event := readingChange(key, previous, current); event != nil {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// locationChange returns a LocationChange if current is far enough from the reference location.
+func (w *watcher) locationChange(current *Location) WatchEvent {
+	if w.reference == nil {
+		w.reference = current
+		return nil
+	}
+	meters := w.reference.DistanceTo(current)
+	if meters < w.minDistance {
+		return nil
+	}
+	event := &LocationChange{Previous: w.reference, Current: current, Meters: meters}
+	w.reference = current
+	return event
+}
+
+// readingChange returns the event for a reading that is newer than previous, or nil if nothing changed.
+func readingChange(key Key, previous, current batchItem) WatchEvent {
+	switch current := current.(type) {
+	case *Charge:
+		previous := previous.(*Charge)
+		if previous.State != current.State || previous.IsPluggedIn != current.IsPluggedIn {
+			return &ChargeChange{Previous: previous, Current: current}
+		}
+	case *Ignition:
+		previous := previous.(*Ignition)
+		if previous.State != current.State || previous.IsEngineRunning != current.IsEngineRunning {
+			return &IgnitionChange{Previous: previous, Current: current}
+		}
+	case *Odometer:
+		previous := previous.(*Odometer)
+		delta := current.Distance.Value - previous.Distance.In(current.Distance.Units).Value
+		if delta > 0 {
+			return &OdometerChange{Previous: previous, Current: current, Delta: Distance{Value: delta, Units: current.Distance.Units}}
+		}
+	default:
+		if !sameReading(previous, current) {
+			return &ReadingChange{Key: key, Previous: previous, Current: current}
+		}
+	}
+	return nil
+}
+
+// isNewer reports whether current is a newer reading than previous. Readings without a DataAge are always newer.
+func isNewer(current, previous batchItem) bool {
+	currentAge, previousAge := current.responseHeaders().DataAge, previous.responseHeaders().DataAge
+	return currentAge.IsZero() || previousAge.IsZero() || currentAge.After(previousAge)
+}
+
+// isRollback reports whether current is an odometer reading lower than previous.
+func isRollback(previous, current batchItem) bool {
+	previousOdometer, ok := previous.(*Odometer)
+	currentOdometer, currentOK := current.(*Odometer)
+	return ok && currentOK && currentOdometer.Distance.Value < previousOdometer.Distance.In(currentOdometer.Distance.Units).Value
+}
+
+// sameReading compares two responses of the same type, ignoring their ResponseHeaders.
+func sameReading(a, b batchItem) bool {
+	return reflect.DeepEqual(withoutHeaders(a), withoutHeaders(b))
+}
+
+// withoutHeaders returns a copy of item with empty ResponseHeaders.
+func withoutHeaders(item batchItem) interface{} {
+	value := reflect.New(reflect.TypeOf(item).Elem())
+	value.Elem().Set(reflect.ValueOf(item).Elem())
+	*value.Interface().(batchItem).responseHeaders() = ResponseHeaders{}
+	return value.Interface()
+}
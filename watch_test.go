@@ -0,0 +1,236 @@
+package smartcar
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatchTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel context.CancelFunc
+	start  time.Time
+}
+
+// scriptedVehicle returns the scripted Batch responses in order, repeating the last one forever.
+type scriptedVehicle struct {
+	Vehicle
+	mu        sync.Mutex
+	responses []scriptedBatch
+	calls     int
+}
+
+type scriptedBatch struct {
+	data *Data
+	err  error
+}
+
+func (v *scriptedVehicle) Batch(ctx context.Context, keys ...Key) (*Data, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	res := v.responses[len(v.responses)-1]
+	if v.calls < len(v.responses) {
+		res = v.responses[v.calls]
+	}
+	v.calls++
+	return res.data, res.err
+}
+
+func (s *WatchTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	s.start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *WatchTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *WatchTestSuite) headers(minutes int) ResponseHeaders {
+	return ResponseHeaders{DataAge: s.start.Add(time.Duration(minutes) * time.Minute), UnitSystem: Metric}
+}
+
+func (s *WatchTestSuite) watch(responses []scriptedBatch, keys ...Key) <-chan WatchEvent {
+	v := &scriptedVehicle{responses: responses}
+	return Watch(s.ctx, v, keys, time.Millisecond, WithJitter(0), WithMaxBackoff(4*time.Millisecond))
+}
+
+func (s *WatchTestSuite) next(events <-chan WatchEvent) WatchEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-s.ctx.Done():
+		s.FailNow("no event received")
+		return nil
+	}
+}
+
+func (s *WatchTestSuite) TestLocationChange() {
+	at := func(minutes int, latitude float64) scriptedBatch {
+		return scriptedBatch{data: &Data{Location: &Location{Latitude: latitude, Longitude: 0, ResponseHeaders: s.headers(minutes)}}}
+	}
+	// 0.0001 degrees of latitude are about 11 meters.
+	events := s.watch([]scriptedBatch{
+		at(0, 0),
+		at(1, 0.0001),
+		at(2, 0.0003),
+		at(3, 0.001),
+	}, LocationPath)
+
+	event := s.next(events).(*LocationChange)
+
+	assert.Equal(s.T(), 0.0, event.Previous.Latitude)
+	assert.Equal(s.T(), 0.001, event.Current.Latitude)
+	assert.InDelta(s.T(), 111, event.Meters, 1)
+}
+
+func (s *WatchTestSuite) TestFailedPath() {
+	at := func(minutes int, latitude float64) scriptedBatch {
+		return scriptedBatch{data: &Data{Location: &Location{Latitude: latitude, Longitude: 2.35, ResponseHeaders: s.headers(minutes)}}}
+	}
+	failed := decodeBatchResponses([]batchResponseItem{{
+		Path: string(LocationPath),
+		Code: http.StatusConflict,
+		Body: map[string]interface{}{"type": "VEHICLE_STATE", "code": "ASLEEP"},
+	}})
+	events := s.watch([]scriptedBatch{
+		at(0, 48.85),
+		{data: failed},
+		at(2, 48.851),
+	}, LocationPath)
+
+	event := s.next(events).(*LocationChange)
+
+	// The failed path is not a location at 0, 0.
+	assert.Equal(s.T(), 48.85, event.Previous.Latitude)
+	assert.Equal(s.T(), 48.851, event.Current.Latitude)
+	assert.InDelta(s.T(), 111, event.Meters, 1)
+}
+
+func (s *WatchTestSuite) TestDataAgeDeduplication() {
+	events := s.watch([]scriptedBatch{
+		{data: &Data{Charge: &Charge{State: "NOT_CHARGING", ResponseHeaders: s.headers(0)}}},
+		// Same data age, the reading is considered a duplicate even though it changed.
+		{data: &Data{Charge: &Charge{State: "CHARGING", ResponseHeaders: s.headers(0)}}},
+		{data: &Data{Charge: &Charge{State: "CHARGING", IsPluggedIn: true, ResponseHeaders: s.headers(1)}}},
+	}, ChargePath)
+
+	event := s.next(events).(*ChargeChange)
+
+	assert.Equal(s.T(), "NOT_CHARGING", event.Previous.State)
+	assert.False(s.T(), event.Previous.IsPluggedIn)
+	assert.Equal(s.T(), "CHARGING", event.Current.State)
+	assert.True(s.T(), event.Current.IsPluggedIn)
+}
+
+func (s *WatchTestSuite) TestOdometerAndReadingChanges() {
+	reading := func(minutes int, kilometers, percent float64) scriptedBatch {
+		return scriptedBatch{data: &Data{
+			Odometer: &Odometer{Distance: Distance{Value: kilometers, Units: Metric}, ResponseHeaders: s.headers(minutes)},
+			Battery:  &Battery{PercentRemaining: percent, ResponseHeaders: s.headers(minutes)},
+		}}
+	}
+	events := s.watch([]scriptedBatch{
+		reading(0, 100, 0.8),
+		// A rollback does not emit an event, the battery did not change either.
+		reading(1, 90, 0.8),
+		reading(2, 102.5, 0.7),
+	}, OdometerPath, BatteryPath)
+
+	odometer := s.next(events).(*OdometerChange)
+	battery := s.next(events).(*ReadingChange)
+
+	// The rollback is not kept as the previous reading either.
+	assert.Equal(s.T(), 100.0, odometer.Previous.Distance.Value)
+	assert.Equal(s.T(), Distance{Value: 2.5, Units: Metric}, odometer.Delta)
+	assert.Equal(s.T(), BatteryPath, battery.Key)
+	assert.Equal(s.T(), 0.8, battery.Previous.(*Battery).PercentRemaining)
+	assert.Equal(s.T(), 0.7, battery.Current.(*Battery).PercentRemaining)
+}
+
+func (s *WatchTestSuite) TestIgnitionChange() {
+	events := s.watch([]scriptedBatch{
+		{data: &Data{Ignition: &Ignition{State: IgnitionOff}}},
+		{data: &Data{Ignition: &Ignition{State: IgnitionOn, IsEngineRunning: true}}},
+	}, IgnitionPath)
+
+	event := s.next(events).(*IgnitionChange)
+
+	assert.Equal(s.T(), IgnitionOff, event.Previous.State)
+	assert.Equal(s.T(), IgnitionOn, event.Current.State)
+}
+
+func (s *WatchTestSuite) TestErrorsBackoff() {
+	failure := errors.New("vehicle is asleep")
+	events := s.watch([]scriptedBatch{
+		{err: failure},
+		{err: failure},
+		{err: failure},
+		{err: failure},
+		{data: &Data{Charge: &Charge{State: "NOT_CHARGING"}}},
+		{data: &Data{Charge: &Charge{State: "CHARGING"}}},
+	}, ChargePath)
+
+	var retries []time.Duration
+	for i := 1; i <= 4; i++ {
+		event := s.next(events).(*WatchError)
+		assert.Equal(s.T(), failure, event.Err)
+		assert.Equal(s.T(), i, event.Attempt)
+		retries = append(retries, event.Retry)
+	}
+	_, ok := s.next(events).(*ChargeChange)
+
+	assert.Equal(s.T(), []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond}, retries)
+	assert.True(s.T(), ok)
+}
+
+func (s *WatchTestSuite) TestShutdown() {
+	events := s.watch([]scriptedBatch{{data: &Data{}}}, ChargePath)
+
+	s.cancel()
+
+	for range events {
+	}
+	_, open := <-events
+	assert.False(s.T(), open)
+}
+
+func (s *WatchTestSuite) TestJitter() {
+	w := &watcher{jitter: 0.5, random: rand.New(rand.NewSource(1))}
+
+	for i := 0; i < 100; i++ {
+		wait := w.withJitter(time.Second)
+		assert.True(s.T(), wait >= 500*time.Millisecond && wait <= 1500*time.Millisecond)
+	}
+}
+
+func (s *WatchTestSuite) TestNonPositiveInterval() {
+	zero := newWatcher(nil, nil, 0)
+	negative := newWatcher(nil, nil, -time.Second, WithMaxBackoff(0))
+
+	assert.Equal(s.T(), defaultWatchInterval, zero.interval)
+	assert.Equal(s.T(), defaultWatchInterval, negative.interval)
+	assert.Equal(s.T(), defaultWatchMaxBackoff, negative.backoff(10))
+}
+
+func (s *WatchTestSuite) TestJitterClamp() {
+	above := newWatcher(nil, nil, time.Second, WithJitter(1.5))
+	below := newWatcher(nil, nil, time.Second, WithJitter(-0.5))
+
+	assert.Equal(s.T(), maxWatchJitter, above.jitter)
+	assert.Equal(s.T(), 0.0, below.jitter)
+	for i := 0; i < 100; i++ {
+		assert.True(s.T(), above.withJitter(time.Second) >= 100*time.Millisecond)
+	}
+}
+
+func TestWatchTestSuite(t *testing.T) {
+	suite.Run(t, new(WatchTestSuite))
+}
@@ -0,0 +1,263 @@
+package smartcar
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// SignatureHeader is the header of a webhook request that contains the signature of its payload.
+const SignatureHeader = "SC-Signature"
+
+// defaultWebhookMaxBodyBytes is the largest webhook body accepted when WebhookHandlerParams.MaxBodyBytes is not set.
+const defaultWebhookMaxBodyBytes = 1 << 20
+
+// Names of the webhook events, as sent in WebhookEvent.EventName.
+const (
+	WebhookEventVerify   = "verify"
+	WebhookEventSchedule = "schedule"
+)
+
+// WebhookEvent contains the fields shared by every webhook delivery.
+type WebhookEvent struct {
+	Version   string `json:"version"`
+	WebhookID string `json:"webhookId"`
+	EventName string `json:"eventName"`
+	Mode      string `json:"mode"`
+}
+
+// ScheduleEvent is dispatched once per vehicle of a schedule based webhook delivery. Data only contains the paths
+// that were read successfully, the other paths are dispatched as a VehicleErrorEvent.
+type ScheduleEvent struct {
+	WebhookEvent
+	VehicleID string
+	RequestID string
+	Data      *Data
+}
+
+// VehicleErrorEvent is dispatched when a webhook delivery reports an error for a vehicle. Path is empty when
+// none of the data of the vehicle could be read.
+type VehicleErrorEvent struct {
+	WebhookEvent
+	VehicleID  string
+	RequestID  string
+	Path       string
+	StatusCode int
+	Type       string
+	Code       string
+	Message    string
+}
+
+// WebhookHandlerParams is a param in client.NewWebhookHandler
+type WebhookHandlerParams struct {
+	ManagementToken string
+	// MaxBodyBytes limits the size of webhook requests, it defaults to 1MB.
+	MaxBodyBytes int64
+}
+
+// WebhookHandler is an http.Handler for a Smartcar webhook URL. It rejects requests without a valid SC-Signature,
+// including verification challenges, answers challenges and dispatches the decoded events to the registered
+// callbacks. A callback returning an error makes the handler respond with a 500, so Smartcar retries the delivery.
+// The whole payload is delivered again, including the vehicles dispatched before the failed callback: callbacks
+// must be idempotent, i.e. by skipping the events whose RequestID they already processed.
+type WebhookHandler interface {
+	http.Handler
+	OnSchedule(func(context.Context, *ScheduleEvent) error)
+	OnVehicleError(func(context.Context, *VehicleErrorEvent) error)
+}
+
+// webhookHandler implements the WebhookHandler interface.
+type webhookHandler struct {
+	managementToken string
+	maxBodyBytes    int64
+
+	mu             sync.RWMutex
+	onSchedule     func(context.Context, *ScheduleEvent) error
+	onVehicleError func(context.Context, *VehicleErrorEvent) error
+}
+
+// webhookRequest is the body of a webhook request.
+type webhookRequest struct {
+	WebhookEvent
+	Payload json.RawMessage `json:"payload"`
+}
+
+type webhookVerifyPayload struct {
+	Challenge string `json:"challenge"`
+}
+
+type webhookSchedulePayload struct {
+	Vehicles []struct {
+		VehicleID string              `json:"vehicleId"`
+		RequestID string              `json:"requestId"`
+		Data      []batchResponseItem `json:"data"`
+		Error     interface{}         `json:"error"`
+	} `json:"vehicles"`
+}
+
+// OnSchedule registers the callback for schedule based deliveries.
+func (h *webhookHandler) OnSchedule(callback func(context.Context, *ScheduleEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onSchedule = callback
+}
+
+// OnVehicleError registers the callback for vehicle errors.
+func (h *webhookHandler) OnVehicleError(callback func(context.Context, *VehicleErrorEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onVehicleError = callback
+}
+
+// ServeHTTP handles a webhook request.
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, h.maxBodyBytes+1))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > h.maxBodyBytes {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Verification requests are signed too. Answering an unsigned challenge would sign any body chosen by the
+	// sender, since the answer is the same HMAC as the signature of a delivery.
+	if !verifyPayload(h.managementToken, r.Header.Get(SignatureHeader), body) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	req := new(webhookRequest)
+	if err := json.Unmarshal(body, req); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	switch req.EventName {
+	case WebhookEventVerify:
+		h.serveVerify(w, req)
+	case WebhookEventSchedule:
+		h.serveSchedule(r.Context(), w, req)
+	default:
+		// Unknown events are acknowledged so Smartcar does not keep retrying them.
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// serveVerify answers a verification challenge.
+func (h *webhookHandler) serveVerify(w http.ResponseWriter, req *webhookRequest) {
+	payload := new(webhookVerifyPayload)
+	if err := json.Unmarshal(req.Payload, payload); err != nil || payload.Challenge == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"challenge": hashChallenge(h.managementToken, []byte(payload.Challenge)),
+	})
+}
+
+// serveSchedule dispatches a schedule based delivery, one event per vehicle.
+func (h *webhookHandler) serveSchedule(ctx context.Context, w http.ResponseWriter, req *webhookRequest) {
+	payload := new(webhookSchedulePayload)
+	if err := json.Unmarshal(req.Payload, payload); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	onSchedule, onVehicleError := h.onSchedule, h.onVehicleError
+	h.mu.RUnlock()
+
+	for _, v := range payload.Vehicles {
+		var errorEvents []*VehicleErrorEvent
+		if v.Error != nil {
+			vehicleErr := new(Error)
+			vehicleErr.decodeBody(v.Error)
+			errorEvents = append(errorEvents, &VehicleErrorEvent{
+				WebhookEvent: req.WebhookEvent,
+				VehicleID:    v.VehicleID,
+				RequestID:    v.RequestID,
+				Type:         vehicleErr.Type,
+				Code:         vehicleErr.Code,
+				Message:      vehicleErr.Message,
+			})
+		}
+
+		var responses []batchResponseItem
+		for _, item := range v.Data {
+			itemErr := batchItemError(item)
+			if itemErr == nil {
+				responses = append(responses, item)
+				continue
+			}
+			errorEvents = append(errorEvents, &VehicleErrorEvent{
+				WebhookEvent: req.WebhookEvent,
+				VehicleID:    v.VehicleID,
+				RequestID:    v.RequestID,
+				Path:         item.Path,
+				StatusCode:   item.Code,
+				Type:         itemErr.Type,
+				Code:         itemErr.Code,
+				Message:      itemErr.Message,
+			})
+		}
+
+		if onSchedule != nil && len(responses) > 0 {
+			err := onSchedule(ctx, &ScheduleEvent{
+				WebhookEvent: req.WebhookEvent,
+				VehicleID:    v.VehicleID,
+				RequestID:    v.RequestID,
+				Data:         decodeBatchResponses(responses),
+			})
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+		if onVehicleError != nil {
+			for _, errorEvent := range errorEvents {
+				if err := onVehicleError(ctx, errorEvent); err != nil {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// hashChallenge returns the hex encoded HMAC-SHA256 of message, keyed with the application management token.
+func hashChallenge(managementToken string, message []byte) string {
+	mac := hmac.New(sha256.New, []byte(managementToken))
+	mac.Write(message)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyPayload checks that signature is the HMAC-SHA256 of body. Signatures are compared in constant time.
+func verifyPayload(managementToken, signature string, body []byte) bool {
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(expected) != sha256.Size {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(managementToken))
+	mac.Write(body)
+	return hmac.Equal(expected, mac.Sum(nil))
+}
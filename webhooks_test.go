@@ -0,0 +1,274 @@
+package smartcar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestHashChallenge(t *testing.T) {
+	// Arrange
+	expectedHash := "73317d612d929825620602937d77100446933a49478dca3050c9d2af0027becd"
+
+	// Act
+	hash := hashChallenge("amt-1234", []byte("challenge-string"))
+
+	// Assert
+	assert.Equal(t, expectedHash, hash)
+}
+
+func TestVerifyPayload(t *testing.T) {
+	// Arrange
+	body := []byte(`{"eventName":"schedule"}`)
+	signature := "9777e2964ece6fef515d598ed398f24366ba3bda969da2080d6c1aa0d696e381"
+
+	// Act & Assert
+	assert.True(t, verifyPayload("amt-1234", signature, body))
+	assert.True(t, verifyPayload("amt-1234", strings.ToUpper(signature), body))
+	assert.False(t, verifyPayload("other-amt", signature, body))
+	assert.False(t, verifyPayload("amt-1234", signature, []byte(`{"eventName":"verify"}`)))
+}
+
+func TestVerifyPayloadMalformedSignature(t *testing.T) {
+	// Arrange
+	body := []byte(`{"eventName":"schedule"}`)
+
+	// Act & Assert
+	assert.False(t, verifyPayload("amt-1234", "", body))
+	assert.False(t, verifyPayload("amt-1234", "not-hex", body))
+	assert.False(t, verifyPayload("amt-1234", "9777e2964ece6fef", body))
+}
+
+type WebhookHandlerTestSuite struct {
+	suite.Suite
+	handler         WebhookHandler
+	managementToken string
+	schedules       []*ScheduleEvent
+	vehicleErrors   []*VehicleErrorEvent
+}
+
+func (s *WebhookHandlerTestSuite) SetupTest() {
+	s.managementToken = "amt-1234"
+	s.schedules = nil
+	s.vehicleErrors = nil
+	s.handler = NewClient().NewWebhookHandler(&WebhookHandlerParams{
+		ManagementToken: s.managementToken,
+		MaxBodyBytes:    2048,
+	})
+	s.handler.OnSchedule(func(ctx context.Context, event *ScheduleEvent) error {
+		s.schedules = append(s.schedules, event)
+		return nil
+	})
+	s.handler.OnVehicleError(func(ctx context.Context, event *VehicleErrorEvent) error {
+		s.vehicleErrors = append(s.vehicleErrors, event)
+		return nil
+	})
+}
+
+func (s *WebhookHandlerTestSuite) serve(method string, body []byte, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/webhook", bytes.NewReader(body))
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *WebhookHandlerTestSuite) serveSigned(payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	return s.serve(http.MethodPost, body, hashChallenge(s.managementToken, body))
+}
+
+func (s *WebhookHandlerTestSuite) TestVerify() {
+	body := []byte(`{"version":"2.0","webhookId":"webhook-id","eventName":"verify","payload":{"challenge":"challenge-string"}}`)
+
+	rec := s.serve(http.MethodPost, body, hashChallenge(s.managementToken, body))
+
+	assert.Equal(s.T(), http.StatusOK, rec.Code)
+	assert.JSONEq(s.T(), `{"challenge":"73317d612d929825620602937d77100446933a49478dca3050c9d2af0027becd"}`, rec.Body.String())
+}
+
+func (s *WebhookHandlerTestSuite) TestVerifyMissingChallenge() {
+	rec := s.serveSigned(map[string]interface{}{"eventName": "verify", "payload": map[string]interface{}{}})
+
+	assert.Equal(s.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestVerifyUnsigned() {
+	// The answer to a challenge is the HMAC of the challenge, so an unsigned challenge must not be answered:
+	// its answer would be a valid signature for a forged delivery.
+	forged := []byte(`{"eventName":"schedule","payload":{"vehicles":[{"vehicleId":"vehicle-id","data":[]}]}}`)
+	challenge, _ := json.Marshal(map[string]interface{}{
+		"eventName": "verify",
+		"payload":   map[string]string{"challenge": string(forged)},
+	})
+
+	verifyRec := s.serve(http.MethodPost, challenge, "")
+	forgedRec := s.serve(http.MethodPost, forged, verifyRec.Body.String())
+
+	assert.Equal(s.T(), http.StatusUnauthorized, verifyRec.Code)
+	assert.NotContains(s.T(), verifyRec.Body.String(), hashChallenge(s.managementToken, forged))
+	assert.Equal(s.T(), http.StatusUnauthorized, forgedRec.Code)
+	assert.Empty(s.T(), s.schedules)
+	assert.Empty(s.T(), s.vehicleErrors)
+}
+
+func (s *WebhookHandlerTestSuite) TestSchedule() {
+	mockDistance := 1234.5
+	expectedEvent := &ScheduleEvent{
+		WebhookEvent: WebhookEvent{
+			Version:   "2.0",
+			WebhookID: "webhook-id",
+			EventName: WebhookEventSchedule,
+			Mode:      "live",
+		},
+		VehicleID: "vehicle-id",
+		RequestID: "request-id",
+		Data: &Data{
+			Odometer: &Odometer{
+				Distance: Distance{Value: mockDistance, Units: Imperial},
+				ResponseHeaders: ResponseHeaders{
+					DataAge:    time.Date(2018, 6, 20, 1, 33, 37, 78000000, time.UTC),
+					UnitSystem: Imperial,
+				},
+			},
+		},
+	}
+	payload := map[string]interface{}{
+		"version":   "2.0",
+		"webhookId": "webhook-id",
+		"eventName": "schedule",
+		"mode":      "live",
+		"payload": map[string]interface{}{
+			"vehicles": []interface{}{
+				map[string]interface{}{
+					"vehicleId": "vehicle-id",
+					"requestId": "request-id",
+					"data": []interface{}{
+						map[string]interface{}{
+							"path": "/odometer",
+							"code": 200,
+							"body": map[string]interface{}{"distance": mockDistance},
+							"headers": map[string]interface{}{
+								"sc-data-age":    "2018-06-20T01:33:37.078Z",
+								"sc-unit-system": "imperial",
+							},
+						},
+						map[string]interface{}{
+							"path": "/location",
+							"code": 409,
+							"body": map[string]interface{}{
+								"type":        "VEHICLE_STATE",
+								"code":        "ASLEEP",
+								"description": "The vehicle is asleep.",
+							},
+						},
+					},
+				},
+				map[string]interface{}{
+					"vehicleId": "other-vehicle-id",
+					"error": map[string]interface{}{
+						"type":        "AUTHENTICATION",
+						"description": "The vehicle was disconnected.",
+					},
+				},
+			},
+		},
+	}
+
+	rec := s.serveSigned(payload)
+
+	assert.Equal(s.T(), http.StatusOK, rec.Code)
+	assert.Equal(s.T(), []*ScheduleEvent{expectedEvent}, s.schedules)
+	assert.Len(s.T(), s.vehicleErrors, 2)
+	assert.Equal(s.T(), "vehicle-id", s.vehicleErrors[0].VehicleID)
+	assert.Equal(s.T(), "/location", s.vehicleErrors[0].Path)
+	assert.Equal(s.T(), 409, s.vehicleErrors[0].StatusCode)
+	assert.Equal(s.T(), "VEHICLE_STATE", s.vehicleErrors[0].Type)
+	assert.Equal(s.T(), "ASLEEP", s.vehicleErrors[0].Code)
+	assert.Equal(s.T(), "other-vehicle-id", s.vehicleErrors[1].VehicleID)
+	assert.Equal(s.T(), "", s.vehicleErrors[1].Path)
+	assert.Equal(s.T(), "AUTHENTICATION", s.vehicleErrors[1].Type)
+	assert.Equal(s.T(), "The vehicle was disconnected.", s.vehicleErrors[1].Message)
+}
+
+func (s *WebhookHandlerTestSuite) TestScheduleCallbackError() {
+	s.handler.OnSchedule(func(ctx context.Context, event *ScheduleEvent) error {
+		return errors.New("database is down")
+	})
+	payload := map[string]interface{}{
+		"eventName": "schedule",
+		"payload": map[string]interface{}{
+			"vehicles": []interface{}{
+				map[string]interface{}{
+					"vehicleId": "vehicle-id",
+					"data": []interface{}{
+						map[string]interface{}{"path": "/vin", "code": 200, "body": map[string]interface{}{"vin": "vin"}},
+					},
+				},
+			},
+		},
+	}
+
+	rec := s.serveSigned(payload)
+
+	assert.Equal(s.T(), http.StatusInternalServerError, rec.Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestInvalidSignature() {
+	body := []byte(`{"eventName":"schedule","payload":{"vehicles":[]}}`)
+
+	rec := s.serve(http.MethodPost, body, hashChallenge("other-amt", body))
+
+	assert.Equal(s.T(), http.StatusUnauthorized, rec.Code)
+	assert.Empty(s.T(), s.schedules)
+}
+
+func (s *WebhookHandlerTestSuite) TestMissingSignature() {
+	rec := s.serve(http.MethodPost, []byte(`{"eventName":"schedule","payload":{"vehicles":[]}}`), "")
+
+	assert.Equal(s.T(), http.StatusUnauthorized, rec.Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestUnknownEvent() {
+	rec := s.serveSigned(map[string]interface{}{"eventName": "something_new", "payload": map[string]interface{}{}})
+
+	assert.Equal(s.T(), http.StatusOK, rec.Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestMethodNotAllowed() {
+	rec := s.serve(http.MethodGet, nil, "")
+
+	assert.Equal(s.T(), http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(s.T(), http.MethodPost, rec.Header().Get("Allow"))
+}
+
+func (s *WebhookHandlerTestSuite) TestBodyTooLarge() {
+	body := []byte(`{"eventName":"verify","payload":{"challenge":"` + strings.Repeat("a", 4096) + `"}}`)
+
+	rec := s.serve(http.MethodPost, body, "")
+
+	assert.Equal(s.T(), http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestMalformedJSON() {
+	body := []byte(`{"eventName":`)
+
+	rec := s.serve(http.MethodPost, body, hashChallenge(s.managementToken, body))
+
+	assert.Equal(s.T(), http.StatusBadRequest, rec.Code)
+}
+
+func TestWebhookHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookHandlerTestSuite))
+}